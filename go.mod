@@ -9,6 +9,7 @@ require (
 	github.com/go-chi/chi v1.5.4
 	github.com/go-chi/render v1.0.2
 	github.com/golang-jwt/jwt/v5 v5.0.0
+	github.com/gorilla/websocket v1.5.0
 	github.com/jackc/pgx/v5 v5.3.1
 	github.com/lazada/awg v0.0.0-20170503081044-a74bd21c8359
 	github.com/oshokin/russian-name-generator v1.1.2
@@ -6,16 +6,22 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
+	session_repo "github.com/oshokin/hive-backend/internal/repository/session"
 	"github.com/oshokin/hive-backend/internal/service/common"
+	user_service "github.com/oshokin/hive-backend/internal/service/user"
 )
 
 type (
 	// UserClaims contains JWT claims for a user.
 	UserClaims struct {
-		UserID int64 `json:"user_id"`
+		UserID    int64  `json:"user_id"`
+		FamilyID  string `json:"family_id,omitempty"`
+		SessionID string `json:"session_id,omitempty"`
+		Role      string `json:"role,omitempty"`
 		jwt.RegisteredClaims
 	}
 
@@ -23,101 +29,186 @@ type (
 )
 
 const (
-	cacheExpirationTime               = 15 * time.Minute
-	cacheCleanupInterval              = 5 * time.Minute
 	accessTokenDuration               = 15 * time.Minute
 	refreshTokenDuration              = 24 * time.Hour
 	accessTokenCookieName             = "access_token"
 	refreshTokenCookieName            = "refresh_token"
 	userIDHeader           userIDType = "user_id"
+	sessionHeader          userIDType = "session"
+	roleHeader             userIDType = "role"
+	authorizationHeader               = "Authorization"
+	bearerPrefix                      = "Bearer "
 )
 
-var errAccessDenied = common.NewError(common.ErrStatusUnauthorized, errors.New("access denied"))
+var (
+	errAccessDenied    = common.NewError(common.ErrStatusUnauthorized, errors.New("access denied"))
+	errNotEnoughRights = common.NewError(common.ErrStatusForbidden, errors.New("not enough rights"))
+)
 
+// authMiddleware authenticates the request either by the access token passed in the Authorization header
+// or by the pair of access and refresh token cookies.
 func (s *server) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		accessTokenCookie, err := r.Cookie(accessTokenCookieName)
+		ctx, err := s.authenticate(r)
 		if err != nil {
-			s.renderError(w, r, errAccessDenied)
+			s.renderError(w, r, err)
 			return
 		}
 
-		refreshTokenCookie, err := r.Cookie(refreshTokenCookieName)
-		if err != nil {
-			s.renderError(w, r, errAccessDenied)
-			return
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// optionalAuthMiddleware authenticates the request like authMiddleware,
+// requests without valid credentials are passed through anonymously.
+func (s *server) optionalAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ctx, err := s.authenticate(r); err == nil {
+			r = r.WithContext(ctx)
 		}
 
-		accessToken := accessTokenCookie.Value
-		accesClaims, err := s.verifyAccessToken(accessToken)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate checks the credentials of the request and returns its context with the current user.
+func (s *server) authenticate(r *http.Request) (context.Context, *common.Error) {
+	accessToken, isBearer := getBearerToken(r)
+	if !isBearer {
+		accessTokenCookie, err := r.Cookie(accessTokenCookieName)
 		if err != nil {
-			s.renderError(w, r, common.NewError(common.ErrStatusUnauthorized, err))
-			return
+			return nil, errAccessDenied
 		}
 
-		refreshToken := refreshTokenCookie.Value
-		refreshClaims, err := s.verifyRefreshToken(refreshToken)
+		accessToken = accessTokenCookie.Value
+	}
+
+	accesClaims, err := s.verifyAccessToken(accessToken)
+	if err != nil {
+		return nil, common.NewError(common.ErrStatusUnauthorized, err)
+	}
+
+	if !isBearer {
+		refreshTokenCookie, err := r.Cookie(refreshTokenCookieName)
 		if err != nil {
-			s.cache.Delete(refreshToken)
-			s.renderError(w, r, common.NewError(common.ErrStatusUnauthorized, err))
-			return
+			return nil, errAccessDenied
 		}
 
-		userID, isFound := s.cache.Get(refreshToken)
-		if !isFound {
-			s.renderError(w, r, errAccessDenied)
-			return
+		refreshClaims, err := s.verifyRefreshToken(refreshTokenCookie.Value)
+		if err != nil {
+			return nil, common.NewError(common.ErrStatusUnauthorized, err)
 		}
 
-		if userID != accesClaims.UserID || accesClaims.UserID != refreshClaims.UserID {
-			s.renderError(w, r, errAccessDenied)
-			return
+		if refreshClaims.ID != accesClaims.SessionID {
+			return nil, errAccessDenied
 		}
+	}
 
-		ctx := r.Context()
-		r = r.WithContext(context.WithValue(ctx, userIDHeader, accesClaims.UserID))
+	ctx := r.Context()
 
-		next.ServeHTTP(w, r)
-	})
+	session, err := s.sessionStore.GetByID(ctx, accesClaims.SessionID)
+	if err != nil {
+		return nil, common.NewError(common.ErrStatusInternalError,
+			fmt.Errorf("failed to get session: %w", err))
+	}
+
+	if session == nil || session.RotatedAt != nil || session.UserID != accesClaims.UserID {
+		return nil, errAccessDenied
+	}
+
+	s.touchSession(ctx, session)
+
+	ctx = context.WithValue(ctx, userIDHeader, accesClaims.UserID)
+	ctx = context.WithValue(ctx, sessionHeader, session)
+	ctx = context.WithValue(ctx, roleHeader, user_service.RoleType(accesClaims.Role))
+
+	return ctx, nil
 }
 
-func (s *server) generateAccessToken(userID int64) (string, error) {
+// requireRole allows the request only if the current user has one of the roles.
+// It must be used after authMiddleware.
+func (s *server) requireRole(roles ...user_service.RoleType) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role := getRoleFromContext(r.Context())
+
+			for _, v := range roles {
+				if v == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			s.renderError(w, r, errNotEnoughRights)
+		})
+	}
+}
+
+// getBearerToken returns the token passed in the Authorization header using the Bearer scheme.
+func getBearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get(authorizationHeader)
+	if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return "", false
+	}
+
+	return strings.TrimSpace(header[len(bearerPrefix):]), true
+}
+
+func getUserIDFromContext(ctx context.Context) int64 {
+	userID, _ := ctx.Value(userIDHeader).(int64)
+	return userID
+}
+
+func getRoleFromContext(ctx context.Context) user_service.RoleType {
+	role, _ := ctx.Value(roleHeader).(user_service.RoleType)
+	return role
+}
+
+func getSessionFromContext(ctx context.Context) *session_repo.Session {
+	session, _ := ctx.Value(sessionHeader).(*session_repo.Session)
+	return session
+}
+
+func (s *server) generateAccessToken(session *session_repo.Session, role user_service.RoleType) (string, error) {
+	tokenID, err := generateRandomID()
+	if err != nil {
+		return "", err
+	}
+
 	now := time.Now()
 	claims := UserClaims{
-		UserID: userID,
+		UserID:    session.UserID,
+		FamilyID:  session.FamilyID,
+		SessionID: session.ID,
+		Role:      string(role),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(accessTokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			Subject:   accessTokenCookieName,
+			ID:        tokenID,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	return token.SignedString(s.jwtSecretKey)
+	return s.jwtKeyring.Sign(claims)
 }
 
-func (s *server) generateRefreshToken(userID int64) (string, error) {
-	now := time.Now()
+func (s *server) generateRefreshToken(session *session_repo.Session) (string, error) {
 	claims := UserClaims{
-		UserID: userID,
+		UserID:   session.UserID,
+		FamilyID: session.FamilyID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(refreshTokenDuration)),
-			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(session.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(session.CreatedAt),
 			Subject:   refreshTokenCookieName,
+			ID:        session.ID,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	return token.SignedString(s.jwtSecretKey)
+	return s.jwtKeyring.Sign(claims)
 }
 
 func (s *server) verifyAccessToken(tokenString string) (*UserClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &UserClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			return s.jwtSecretKey, nil
-		})
+	token, err := jwt.ParseWithClaims(tokenString, &UserClaims{}, s.jwtKeyring.Keyfunc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse access token: %w", err)
 	}
@@ -131,10 +222,7 @@ func (s *server) verifyAccessToken(tokenString string) (*UserClaims, error) {
 }
 
 func (s *server) verifyRefreshToken(tokenString string) (*UserClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &UserClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			return s.jwtSecretKey, nil
-		})
+	token, err := jwt.ParseWithClaims(tokenString, &UserClaims{}, s.jwtKeyring.Keyfunc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse refresh token: %w", err)
 	}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+	user_service "github.com/oshokin/hive-backend/internal/service/user"
+)
+
+type (
+	changePasswordRequest struct {
+		OldPassword string `json:"old_password"`
+		NewPassword string `json:"new_password"`
+	}
+
+	changePasswordResponse struct {
+		Success bool `json:"success"`
+	}
+)
+
+// changePasswordHandler changes the password of the current user
+// and revokes all their sessions, including the current one.
+func (s *server) changePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	var req changePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.renderError(w, r,
+			common.NewError(common.ErrStatusBadRequest,
+				fmt.Errorf("failed to decode request: %w", err)))
+
+		return
+	}
+
+	err := s.userService.ChangePassword(ctx, &user_service.ChangePasswordRequest{
+		UserID:      userID,
+		OldPassword: req.OldPassword,
+		NewPassword: req.NewPassword,
+	})
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to change password: %w", err)))
+		}
+
+		return
+	}
+
+	if err = s.sessionStore.DeleteByUserID(ctx, userID, ""); err != nil {
+		s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+			fmt.Errorf("failed to revoke sessions: %w", err)))
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &changePasswordResponse{
+		Success: true,
+	})
+}
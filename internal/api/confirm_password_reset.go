@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+	user_service "github.com/oshokin/hive-backend/internal/service/user"
+)
+
+type (
+	confirmPasswordResetRequest struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"new_password"`
+	}
+
+	confirmPasswordResetResponse struct {
+		Success bool `json:"success"`
+	}
+)
+
+// confirmPasswordResetHandler sets a new password using a password reset token
+// and revokes all sessions of the user.
+func (s *server) confirmPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+	var req confirmPasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.renderError(w, r,
+			common.NewError(common.ErrStatusBadRequest,
+				fmt.Errorf("failed to decode request: %w", err)))
+
+		return
+	}
+
+	ctx := r.Context()
+
+	userID, err := s.userService.ResetPassword(ctx, &user_service.ResetPasswordRequest{
+		Token:       req.Token,
+		NewPassword: req.NewPassword,
+	})
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to reset password: %w", err)))
+		}
+
+		return
+	}
+
+	if err = s.sessionStore.DeleteByUserID(ctx, userID, ""); err != nil {
+		s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+			fmt.Errorf("failed to revoke sessions: %w", err)))
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &confirmPasswordResetResponse{
+		Success: true,
+	})
+}
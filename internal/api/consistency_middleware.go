@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/oshokin/hive-backend/internal/db"
+)
+
+// consistencyTokenHeader carries the position in the write-ahead log of the last write made by the client.
+// It's returned after writes and should be sent back with further requests,
+// so that they read from replicas that have replayed the writes or from the master.
+const consistencyTokenHeader = "X-Consistency-Token"
+
+// consistencyMiddleware provides read-your-writes consistency to the clients that pass the consistency token.
+// Invalid tokens are ignored, so that a client can't break its reads with a stale or malformed one.
+func (s *server) consistencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			minLSN, _ = db.ParseLSN(r.Header.Get(consistencyTokenHeader))
+			mu        sync.Mutex
+			isServed  bool
+		)
+
+		consistency := db.NewConsistency(minLSN, func(lsn db.LSN) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			// Writes made in background after the response aren't reported.
+			if !isServed {
+				w.Header().Set(consistencyTokenHeader, lsn.String())
+			}
+		})
+
+		next.ServeHTTP(w, r.WithContext(db.WithConsistency(r.Context(), consistency)))
+
+		mu.Lock()
+		isServed = true
+		mu.Unlock()
+	})
+}
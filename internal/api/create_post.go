@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+	post_service "github.com/oshokin/hive-backend/internal/service/post"
+)
+
+type (
+	createPostRequest struct {
+		Text string `json:"text"`
+	}
+
+	createPostResponse struct {
+		PostID int64 `json:"post_id"`
+	}
+)
+
+func (s *server) createPostHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	var (
+		req createPostRequest
+		err = json.NewDecoder(r.Body).Decode(&req)
+	)
+
+	if err != nil {
+		s.renderError(w, r,
+			common.NewError(common.ErrStatusBadRequest,
+				fmt.Errorf("failed to decode request: %w", err)))
+
+		return
+	}
+
+	postID, err := s.postService.Create(ctx, &post_service.Post{
+		AuthorID: userID,
+		Text:     req.Text,
+	})
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to create post: %w", err)))
+		}
+
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, &createPostResponse{
+		PostID: postID,
+	})
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+)
+
+type deleteCurrentUserResponse struct {
+	Success bool `json:"success"`
+}
+
+// deleteCurrentUserHandler marks the current user as deleted, revokes all their sessions
+// and drops the cached feeds of their followers, so that their posts disappear from the feeds.
+// The user is purged permanently after the retention period.
+func (s *server) deleteCurrentUserHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	err := s.userService.Delete(ctx, userID)
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to delete user: %w", err)))
+		}
+
+		return
+	}
+
+	if err = s.sessionStore.DeleteByUserID(ctx, userID, ""); err != nil {
+		s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+			fmt.Errorf("failed to revoke sessions: %w", err)))
+
+		return
+	}
+
+	s.feedService.InvalidateFollowers(ctx, userID)
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &deleteCurrentUserResponse{
+		Success: true,
+	})
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+)
+
+type deleteFriendResponse struct {
+	Success bool `json:"success"`
+}
+
+func (s *server) deleteFriendHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	friendID, err := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
+	if err != nil {
+		s.renderError(w, r,
+			common.NewError(common.ErrStatusBadRequest,
+				fmt.Errorf("failed to parse friend ID: %w", err)))
+
+		return
+	}
+
+	err = s.friendService.Delete(ctx, userID, friendID)
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to delete friend: %w", err)))
+		}
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &deleteFriendResponse{
+		Success: true,
+	})
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/oshokin/hive-backend/internal/common"
+	"github.com/oshokin/hive-backend/internal/logger"
+	post_service "github.com/oshokin/hive-backend/internal/service/post"
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// feedPostedHandler upgrades the connection to WebSocket
+// and pushes new posts of the current user's friends to it.
+func (s *server) feedPostedHandler(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r.Context())
+	if userID == 0 {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	// Upgrade writes an error response to the client by itself.
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logger.WarnKV(r.Context(), "failed to upgrade connection to websocket",
+			common.UserIDTag, userID,
+			common.ErrorTag, err)
+
+		return
+	}
+
+	// The request context is cancelled when the handler returns,
+	// so the connection lives in the context of the server.
+	s.wsRegistry.add(s.ctx, newWSConnection(userID, conn))
+}
+
+// dispatchCreatedPosts sends posts published to the bus to connected followers of their authors.
+func (s *server) dispatchCreatedPosts(ctx context.Context, posts <-chan *post_service.Post) {
+	for p := range posts {
+		followerIDs, err := s.friendService.GetFollowerIDs(ctx, p.AuthorID)
+		if err != nil {
+			logger.ErrorKV(ctx, "failed to get followers of post author",
+				common.PostIDTag, p.ID,
+				common.ErrorTag, err)
+
+			continue
+		}
+
+		s.wsRegistry.sendToUsers(ctx, followerIDs, s.getPostModel(p))
+	}
+}
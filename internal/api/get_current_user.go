@@ -0,0 +1,37 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+)
+
+// getCurrentUserHandler returns the profile of the current user.
+func (s *server) getCurrentUserHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	user, err := s.userService.GetByID(ctx, userID)
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to get user info: %w", err)))
+		}
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, s.projectUser(user, viewerOwner))
+}
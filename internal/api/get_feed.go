@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+	feed_service "github.com/oshokin/hive-backend/internal/service/feed"
+)
+
+type getFeedResponse struct {
+	Items   []*Post `json:"items"`
+	HasNext bool    `json:"has_next"`
+}
+
+func (s *server) getFeedHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	var (
+		queryParams    = r.URL.Query()
+		offset, _      = strconv.ParseUint(queryParams.Get("offset"), 10, 64)
+		limit, _       = strconv.ParseUint(queryParams.Get("limit"), 10, 64)
+		serviceRequest = &feed_service.GetRequest{
+			UserID: userID,
+			Offset: offset,
+			Limit:  limit,
+		}
+	)
+
+	res, err := s.feedService.Get(ctx, serviceRequest)
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to get feed: %w", err)))
+		}
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, s.fillGetFeedResponse(res))
+}
+
+func (s *server) fillGetFeedResponse(res *feed_service.GetResponse) *getFeedResponse {
+	if res == nil {
+		return nil
+	}
+
+	items := make([]*Post, 0, len(res.Items))
+
+	for _, v := range res.Items {
+		if v == nil {
+			continue
+		}
+
+		items = append(items, &Post{
+			ID:        v.ID,
+			AuthorID:  v.AuthorID,
+			Text:      v.Text,
+			CreatedAt: v.CreatedAt,
+			UpdatedAt: v.UpdatedAt,
+		})
+	}
+
+	return &getFeedResponse{
+		Items:   items,
+		HasNext: res.HasNext,
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+	friend_service "github.com/oshokin/hive-backend/internal/service/friend"
+)
+
+type (
+	getFriendsItem struct {
+		UserID    int64     `json:"user_id"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	getFriendsResponse struct {
+		Items   []*getFriendsItem `json:"items"`
+		HasNext bool              `json:"has_next"`
+	}
+)
+
+func (s *server) getFriendsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	var (
+		queryParams    = r.URL.Query()
+		limit, _       = strconv.ParseUint(queryParams.Get("limit"), 10, 64)
+		cursor, _      = strconv.ParseInt(queryParams.Get("cursor"), 10, 64)
+		serviceRequest = &friend_service.GetListRequest{
+			UserID: userID,
+			Limit:  limit,
+			Cursor: cursor,
+		}
+	)
+
+	res, err := s.friendService.GetList(ctx, serviceRequest)
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to get friends list: %w", err)))
+		}
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, s.fillGetFriendsResponse(res))
+}
+
+func (s *server) fillGetFriendsResponse(res *friend_service.GetListResponse) *getFriendsResponse {
+	if res == nil {
+		return nil
+	}
+
+	items := make([]*getFriendsItem, 0, len(res.Items))
+
+	for _, v := range res.Items {
+		if v == nil {
+			continue
+		}
+
+		items = append(items, &getFriendsItem{
+			UserID:    v.UserID,
+			CreatedAt: v.CreatedAt,
+		})
+	}
+
+	return &getFriendsResponse{
+		Items:   items,
+		HasNext: res.HasNext,
+	}
+}
@@ -0,0 +1,14 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+// getJWKSHandler publishes public keys used to sign tokens,
+// so that other services could verify them without sharing a secret.
+func (s *server) getJWKSHandler(w http.ResponseWriter, r *http.Request) {
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, s.jwtKeyring.JWKS())
+}
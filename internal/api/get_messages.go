@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+	dialog_service "github.com/oshokin/hive-backend/internal/service/dialog"
+)
+
+type (
+	getMessagesItem struct {
+		ID        int64     `json:"id"`
+		From      int64     `json:"from"`
+		To        int64     `json:"to"`
+		Text      string    `json:"text"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	getMessagesResponse struct {
+		Items   []*getMessagesItem `json:"items"`
+		HasNext bool               `json:"has_next"`
+	}
+)
+
+func (s *server) getMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	partnerID, err := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
+	if err != nil {
+		s.renderError(w, r,
+			common.NewError(common.ErrStatusBadRequest,
+				fmt.Errorf("failed to parse user ID: %w", err)))
+
+		return
+	}
+
+	var (
+		queryParams    = r.URL.Query()
+		limit, _       = strconv.ParseUint(queryParams.Get("limit"), 10, 64)
+		cursor, _      = strconv.ParseInt(queryParams.Get("cursor"), 10, 64)
+		serviceRequest = &dialog_service.GetListRequest{
+			UserID:    userID,
+			PartnerID: partnerID,
+			Limit:     limit,
+			Cursor:    cursor,
+		}
+	)
+
+	res, err := s.dialogService.GetList(ctx, serviceRequest)
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to get messages list: %w", err)))
+		}
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, s.fillGetMessagesResponse(res))
+}
+
+func (s *server) fillGetMessagesResponse(res *dialog_service.GetListResponse) *getMessagesResponse {
+	if res == nil {
+		return nil
+	}
+
+	items := make([]*getMessagesItem, 0, len(res.Items))
+
+	for _, v := range res.Items {
+		if v == nil {
+			continue
+		}
+
+		items = append(items, &getMessagesItem{
+			ID:        v.ID,
+			From:      v.SenderID,
+			To:        v.ReceiverID,
+			Text:      v.Text,
+			CreatedAt: v.CreatedAt,
+		})
+	}
+
+	return &getMessagesResponse{
+		Items:   items,
+		HasNext: res.HasNext,
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+	post_service "github.com/oshokin/hive-backend/internal/service/post"
+)
+
+// Post represents a post entity returned by the API.
+type Post struct {
+	ID        int64      `json:"id"`
+	AuthorID  int64      `json:"author_id"`
+	Text      string     `json:"text"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+func (s *server) getPostHandler(w http.ResponseWriter, r *http.Request) {
+	postID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		s.renderError(w, r,
+			common.NewError(common.ErrStatusBadRequest,
+				fmt.Errorf("failed to parse post ID: %w", err)))
+
+		return
+	}
+
+	post, err := s.postService.GetByID(r.Context(), postID)
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to get post: %w", err)))
+		}
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, s.getPostModel(post))
+}
+
+func (s *server) getPostModel(post *post_service.Post) *Post {
+	if post == nil {
+		return nil
+	}
+
+	return &Post{
+		ID:        post.ID,
+		AuthorID:  post.AuthorID,
+		Text:      post.Text,
+		CreatedAt: post.CreatedAt,
+		UpdatedAt: post.UpdatedAt,
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+	session_repo "github.com/oshokin/hive-backend/internal/repository/session"
+	"github.com/oshokin/hive-backend/internal/service/common"
+)
+
+type (
+	getSessionsItem struct {
+		ID         string    `json:"id"`
+		IssuedAt   time.Time `json:"issued_at"`
+		LastSeenAt time.Time `json:"last_seen_at"`
+		UserAgent  string    `json:"user_agent"`
+		IP         string    `json:"ip"`
+		IsCurrent  bool      `json:"is_current"`
+	}
+
+	getSessionsResponse struct {
+		Items []*getSessionsItem `json:"items"`
+	}
+)
+
+// getSessionsHandler returns active sessions of the current user.
+// A session is identified by its token family, so its ID doesn't change when tokens are refreshed.
+func (s *server) getSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	session := getSessionFromContext(ctx)
+	if session == nil {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	res, err := s.sessionStore.GetActiveByUserID(ctx, session.UserID)
+	if err != nil {
+		s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+			fmt.Errorf("failed to get sessions: %w", err)))
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, s.fillGetSessionsResponse(res, session))
+}
+
+func (s *server) fillGetSessionsResponse(res []*session_repo.Session,
+	current *session_repo.Session) *getSessionsResponse {
+	items := make([]*getSessionsItem, 0, len(res))
+
+	for _, v := range res {
+		if v == nil {
+			continue
+		}
+
+		items = append(items, &getSessionsItem{
+			ID:         v.FamilyID,
+			IssuedAt:   v.CreatedAt,
+			LastSeenAt: v.LastSeenAt,
+			UserAgent:  v.UserAgent,
+			IP:         v.IP,
+			IsCurrent:  v.FamilyID == current.FamilyID,
+		})
+	}
+
+	return &getSessionsResponse{
+		Items: items,
+	}
+}
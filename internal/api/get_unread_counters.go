@@ -0,0 +1,73 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+	counter_service "github.com/oshokin/hive-backend/internal/service/counter"
+)
+
+type (
+	getUnreadCountersItem struct {
+		UserID      int64 `json:"user_id"`
+		UnreadCount int64 `json:"unread_count"`
+	}
+
+	getUnreadCountersResponse struct {
+		Total int64                    `json:"total"`
+		Items []*getUnreadCountersItem `json:"items"`
+	}
+)
+
+func (s *server) getUnreadCountersHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	res, err := s.counterService.Get(ctx, userID)
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to get unread counters: %w", err)))
+		}
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, s.fillGetUnreadCountersResponse(res))
+}
+
+func (s *server) fillGetUnreadCountersResponse(res *counter_service.Counters) *getUnreadCountersResponse {
+	if res == nil {
+		return nil
+	}
+
+	items := make([]*getUnreadCountersItem, 0, len(res.Dialogs))
+
+	for _, v := range res.Dialogs {
+		if v == nil {
+			continue
+		}
+
+		items = append(items, &getUnreadCountersItem{
+			UserID:      v.PartnerID,
+			UnreadCount: v.UnreadCount,
+		})
+	}
+
+	return &getUnreadCountersResponse{
+		Total: res.Total,
+		Items: items,
+	}
+}
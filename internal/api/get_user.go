@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
@@ -13,17 +12,29 @@ import (
 	user_service "github.com/oshokin/hive-backend/internal/service/user"
 )
 
-// User represents a user entity returned by the API.
-type User struct {
-	ID        int64  `json:"id"`
-	Email     string `json:"email"`
-	CityID    int16  `json:"city_id"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Birthdate string `json:"birthdate"`
-	Gender    string `json:"gender"`
-	Interests string `json:"interests"`
-}
+type (
+	// User represents a user entity returned by the API.
+	// Private fields are omitted if the viewer isn't allowed to see them.
+	User struct {
+		ID        int64        `json:"id"`
+		Email     string       `json:"email,omitempty"`
+		CityID    int16        `json:"city_id"`
+		FirstName string       `json:"first_name"`
+		LastName  string       `json:"last_name"`
+		Birthdate string       `json:"birthdate,omitempty"`
+		Gender    string       `json:"gender"`
+		Interests string       `json:"interests,omitempty"`
+		Privacy   *UserPrivacy `json:"privacy,omitempty"`
+	}
+
+	// UserPrivacy represents who can see the private fields of a user profile:
+	// PUBLIC, FRIENDS or ONLY_ME.
+	UserPrivacy struct {
+		Email     string `json:"email"`
+		Birthdate string `json:"birthdate"`
+		Interests string `json:"interests"`
+	}
+)
 
 func (s *server) getUserHandler(w http.ResponseWriter, r *http.Request) {
 	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
@@ -35,7 +46,9 @@ func (s *server) getUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := s.userService.GetByID(r.Context(), userID)
+	ctx := r.Context()
+
+	user, err := s.userService.GetByID(ctx, userID)
 	if err != nil {
 		var e *common.Error
 		if errors.As(err, &e) {
@@ -48,23 +61,19 @@ func (s *server) getUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	render.Status(r, http.StatusOK)
-	render.JSON(w, r, s.getUserModel(user))
-}
+	viewerTypes, err := s.getViewerTypes(ctx, []*user_service.User{user})
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to check who can see user info: %w", err)))
+		}
 
-func (s *server) getUserModel(user *user_service.User) *User {
-	if user == nil {
-		return nil
+		return
 	}
 
-	return &User{
-		ID:        user.ID,
-		Email:     user.Email,
-		CityID:    user.CityID,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Birthdate: user.Birthdate.Format(time.DateOnly),
-		Gender:    string(user.Gender),
-		Interests: user.Interests,
-	}
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, s.projectUser(user, viewerTypes[user.ID]))
 }
@@ -13,14 +13,29 @@ import (
 )
 
 type loginUserRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	TokenMode string `json:"token_mode"`
 }
 
 type loginUserResponse struct {
-	Success bool `json:"success"`
+	Success      bool   `json:"success"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
 }
 
+// Ways of passing issued tokens to the client.
+const (
+	tokenModeCookie = "cookie" // Tokens are set as cookies.
+	tokenModeBody   = "body"   // Tokens are returned in the response body to be sent in the Authorization header.
+	bearerTokenType = "Bearer"
+)
+
+var errUnknownTokenMode = common.NewError(common.ErrStatusBadRequest,
+	fmt.Errorf("token mode must be either %s or %s", tokenModeCookie, tokenModeBody))
+
 func (s *server) loginUserHandler(w http.ResponseWriter, r *http.Request) {
 	var (
 		req loginUserRequest
@@ -35,15 +50,26 @@ func (s *server) loginUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tokenMode := req.TokenMode
+	if tokenMode == "" {
+		tokenMode = tokenModeCookie
+	}
+
+	if tokenMode != tokenModeCookie && tokenMode != tokenModeBody {
+		s.renderError(w, r, errUnknownTokenMode)
+		return
+	}
+
 	var (
 		ctx   = r.Context()
 		creds = &user_service.LoginCredentials{
 			Email:    req.Email,
 			Password: req.Password,
+			IP:       getClientIP(r),
 		}
 	)
 
-	userID, err := s.userService.GetIDByLoginCredentials(ctx, creds)
+	loginData, err := s.userService.GetLoginDataByCredentials(ctx, creds)
 	if err != nil {
 		var e *common.Error
 		if errors.As(err, &e) {
@@ -56,7 +82,23 @@ func (s *server) loginUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	accessToken, err := s.generateAccessToken(userID)
+	familyID, err := generateRandomID()
+	if err != nil {
+		s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+			fmt.Errorf("failed to generate token family ID: %w", err)))
+
+		return
+	}
+
+	session, err := newSession(r, loginData.ID, familyID)
+	if err != nil {
+		s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+			fmt.Errorf("failed to create session: %w", err)))
+
+		return
+	}
+
+	accessToken, err := s.generateAccessToken(session, loginData.Role)
 	if err != nil {
 		s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
 			fmt.Errorf("failed to generate access token: %w", err)))
@@ -64,7 +106,7 @@ func (s *server) loginUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	refreshToken, err := s.generateRefreshToken(userID)
+	refreshToken, err := s.generateRefreshToken(session)
 	if err != nil {
 		s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
 			fmt.Errorf("failed to generate refresh token: %w", err)))
@@ -72,13 +114,33 @@ func (s *server) loginUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.cache.Set(refreshToken, userID, refreshTokenDuration)
-	s.setAuthorizationCookies(w, accessToken, refreshToken)
+	if err = s.sessionStore.Create(ctx, session); err != nil {
+		s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+			fmt.Errorf("failed to save session: %w", err)))
+
+		return
+	}
 
-	render.Status(r, http.StatusOK)
-	render.JSON(w, r, &loginUserResponse{
+	s.renderTokens(w, r, tokenMode, accessToken, refreshToken)
+}
+
+// renderTokens passes issued tokens to the client in the requested way.
+func (s *server) renderTokens(w http.ResponseWriter, r *http.Request, tokenMode, accessToken, refreshToken string) {
+	res := &loginUserResponse{
 		Success: true,
-	})
+	}
+
+	if tokenMode == tokenModeBody {
+		res.AccessToken = accessToken
+		res.RefreshToken = refreshToken
+		res.TokenType = bearerTokenType
+		res.ExpiresIn = int64(accessTokenDuration.Seconds())
+	} else {
+		s.setAuthorizationCookies(w, accessToken, refreshToken)
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, res)
 }
 
 func (s *server) setAuthorizationCookies(w http.ResponseWriter, accessToken, refreshToken string) {
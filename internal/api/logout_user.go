@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/go-chi/render"
@@ -14,35 +15,19 @@ type logoutUserResponse struct {
 func (s *server) logoutUserHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	userID := ctx.Value(userIDHeader)
-	if userID == 0 {
+	session := getSessionFromContext(ctx)
+	if session == nil {
 		s.renderError(w, r, errAccessDenied)
 		return
 	}
 
-	refreshTokenCookie, err := r.Cookie(refreshTokenCookieName)
-	if err != nil {
-		s.renderError(w, r, errAccessDenied)
-		return
-	}
-
-	refreshToken := refreshTokenCookie.Value
-
-	claims, err := s.verifyRefreshToken(refreshToken)
-	if err != nil {
-		s.cache.Delete(refreshToken)
-		s.renderError(w, r, common.NewError(common.ErrStatusUnauthorized, err))
+	if _, err := s.sessionStore.DeleteFamily(ctx, session.UserID, session.FamilyID); err != nil {
+		s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+			fmt.Errorf("failed to delete session: %w", err)))
 
 		return
 	}
 
-	if claims.UserID != userID {
-		s.renderError(w, r, errAccessDenied)
-		return
-	}
-
-	s.cache.Delete(refreshToken)
-
 	render.Status(r, http.StatusOK)
 	render.JSON(w, r, &logoutUserResponse{
 		Success: true,
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+	counter_service "github.com/oshokin/hive-backend/internal/service/counter"
+)
+
+type (
+	readMessagesRequest struct {
+		MessageID int64 `json:"message_id"`
+	}
+
+	readMessagesResponse struct {
+		Success bool `json:"success"`
+	}
+)
+
+func (s *server) readMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	partnerID, err := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
+	if err != nil {
+		s.renderError(w, r,
+			common.NewError(common.ErrStatusBadRequest,
+				fmt.Errorf("failed to parse user ID: %w", err)))
+
+		return
+	}
+
+	// The request body is optional, all messages are marked as read without it.
+	var req readMessagesRequest
+
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.renderError(w, r,
+			common.NewError(common.ErrStatusBadRequest,
+				fmt.Errorf("failed to decode request: %w", err)))
+
+		return
+	}
+
+	err = s.counterService.MarkRead(ctx, &counter_service.MarkReadRequest{
+		UserID:    userID,
+		PartnerID: partnerID,
+		MessageID: req.MessageID,
+	})
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to mark messages as read: %w", err)))
+		}
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &readMessagesResponse{
+		Success: true,
+	})
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/oshokin/hive-backend/internal/common"
+	"github.com/oshokin/hive-backend/internal/logger"
+	session_repo "github.com/oshokin/hive-backend/internal/repository/session"
+	common_service "github.com/oshokin/hive-backend/internal/service/common"
+)
+
+type refreshUserTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+var errRefreshTokenReused = common_service.NewError(common_service.ErrStatusUnauthorized,
+	errors.New("refresh token has already been used, all sessions of this login are revoked"))
+
+// refreshUserTokenHandler exchanges a valid refresh token for a new pair of tokens.
+// The refresh token is taken from the cookie or, if there's no cookie, from the request body;
+// new tokens are passed back the same way.
+// Presenting an already exchanged refresh token is treated as a theft,
+// so the whole token family is revoked.
+func (s *server) refreshUserTokenHandler(w http.ResponseWriter, r *http.Request) {
+	refreshToken, tokenMode, e := getRefreshToken(r)
+	if e != nil {
+		s.renderError(w, r, e)
+		return
+	}
+
+	claims, err := s.verifyRefreshToken(refreshToken)
+	if err != nil {
+		s.renderError(w, r, common_service.NewError(common_service.ErrStatusUnauthorized, err))
+		return
+	}
+
+	ctx := r.Context()
+
+	session, err := s.sessionStore.GetByID(ctx, claims.ID)
+	if err != nil {
+		s.renderError(w, r, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to get session: %w", err)))
+
+		return
+	}
+
+	if session == nil || session.UserID != claims.UserID || session.FamilyID != claims.FamilyID {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	if session.RotatedAt != nil {
+		s.revokeReusedSession(w, r, session)
+		return
+	}
+
+	nextSession, err := newSession(r, session.UserID, session.FamilyID)
+	if err != nil {
+		s.renderError(w, r, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to create session: %w", err)))
+
+		return
+	}
+
+	// The role is read again, so that its changes take effect on the next refresh.
+	user, err := s.userService.GetByID(ctx, session.UserID)
+	if err != nil {
+		var e *common_service.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common_service.NewError(common_service.ErrStatusInternalError,
+				fmt.Errorf("failed to get user: %w", err)))
+		}
+
+		return
+	}
+
+	accessToken, err := s.generateAccessToken(nextSession, user.Role)
+	if err != nil {
+		s.renderError(w, r, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to generate access token: %w", err)))
+
+		return
+	}
+
+	nextRefreshToken, err := s.generateRefreshToken(nextSession)
+	if err != nil {
+		s.renderError(w, r, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to generate refresh token: %w", err)))
+
+		return
+	}
+
+	isRotated, err := s.sessionStore.Rotate(ctx, session.ID, nextSession)
+	if err != nil {
+		s.renderError(w, r, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to rotate session: %w", err)))
+
+		return
+	}
+
+	// The token was exchanged concurrently, which is a reuse as well.
+	if !isRotated {
+		s.revokeReusedSession(w, r, session)
+		return
+	}
+
+	s.renderTokens(w, r, tokenMode, accessToken, nextRefreshToken)
+}
+
+// getRefreshToken returns the refresh token and the way it was passed.
+func getRefreshToken(r *http.Request) (string, string, *common_service.Error) {
+	if refreshTokenCookie, err := r.Cookie(refreshTokenCookieName); err == nil {
+		return refreshTokenCookie.Value, tokenModeCookie, nil
+	}
+
+	var req refreshUserTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return "", "", common_service.NewError(common_service.ErrStatusBadRequest,
+			fmt.Errorf("failed to decode request: %w", err))
+	}
+
+	if req.RefreshToken == "" {
+		return "", "", errAccessDenied
+	}
+
+	return req.RefreshToken, tokenModeBody, nil
+}
+
+func (s *server) revokeReusedSession(w http.ResponseWriter, r *http.Request, session *session_repo.Session) {
+	ctx := r.Context()
+
+	logger.WarnKV(ctx, "refresh token reuse detected",
+		common.UserIDTag, session.UserID)
+
+	if _, err := s.sessionStore.DeleteFamily(ctx, session.UserID, session.FamilyID); err != nil {
+		s.renderError(w, r, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to delete sessions: %w", err)))
+
+		return
+	}
+
+	s.renderError(w, r, errRefreshTokenReused)
+}
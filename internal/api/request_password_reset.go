@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+)
+
+type (
+	requestPasswordResetRequest struct {
+		Email string `json:"email"`
+	}
+
+	requestPasswordResetResponse struct {
+		Success bool `json:"success"`
+	}
+)
+
+// requestPasswordResetHandler sends a password reset token to the user.
+// The response is the same whether the email is registered or not.
+func (s *server) requestPasswordResetHandler(w http.ResponseWriter, r *http.Request) {
+	var req requestPasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.renderError(w, r,
+			common.NewError(common.ErrStatusBadRequest,
+				fmt.Errorf("failed to decode request: %w", err)))
+
+		return
+	}
+
+	if err := s.userService.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to request password reset: %w", err)))
+		}
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &requestPasswordResetResponse{
+		Success: true,
+	})
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+)
+
+type (
+	revokeSessionsRequest struct {
+		SessionID        string `json:"session_id"`
+		AllExceptCurrent bool   `json:"all_except_current"`
+	}
+
+	revokeSessionsResponse struct {
+		Success bool `json:"success"`
+	}
+)
+
+var (
+	errInvalidRevokeSessionsRequest = common.NewError(common.ErrStatusBadRequest,
+		errors.New("either session_id or all_except_current must be specified"))
+	errSessionNotFound = common.NewError(common.ErrStatusNotFound,
+		errors.New("session not found"))
+)
+
+// revokeSessionsHandler revokes either one session of the current user or all of them except the current one.
+func (s *server) revokeSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	session := getSessionFromContext(ctx)
+	if session == nil {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	var req revokeSessionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.renderError(w, r,
+			common.NewError(common.ErrStatusBadRequest,
+				fmt.Errorf("failed to decode request: %w", err)))
+
+		return
+	}
+
+	isSessionIDSet := req.SessionID != ""
+	if isSessionIDSet == req.AllExceptCurrent {
+		s.renderError(w, r, errInvalidRevokeSessionsRequest)
+		return
+	}
+
+	if req.AllExceptCurrent {
+		if err := s.sessionStore.DeleteByUserID(ctx, session.UserID, session.FamilyID); err != nil {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to revoke sessions: %w", err)))
+
+			return
+		}
+	} else {
+		isDeleted, err := s.sessionStore.DeleteFamily(ctx, session.UserID, req.SessionID)
+		if err != nil {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to revoke session: %w", err)))
+
+			return
+		}
+
+		if !isDeleted {
+			s.renderError(w, r, errSessionNotFound)
+			return
+		}
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &revokeSessionsResponse{
+		Success: true,
+	})
+}
@@ -4,21 +4,52 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/render"
 	"github.com/oshokin/hive-backend/internal/service/common"
 	user_service "github.com/oshokin/hive-backend/internal/service/user"
 )
 
-type searchUsersResponse struct {
-	Items   []*User `json:"items"`
-	HasNext bool    `json:"has_next"`
-}
+type (
+	searchUsersResponse struct {
+		Items      []*User       `json:"items"`
+		HasNext    bool          `json:"has_next"`
+		NextCursor string        `json:"next_cursor,omitempty"`
+		Facets     *searchFacets `json:"facets,omitempty"`
+	}
+
+	searchFacets struct {
+		Cities  []*cityFacet   `json:"cities"`
+		Genders []*genderFacet `json:"genders"`
+	}
+
+	cityFacet struct {
+		CityID int16 `json:"city_id"`
+		Count  int64 `json:"count"`
+	}
+
+	genderFacet struct {
+		Gender string `json:"gender"`
+		Count  int64  `json:"count"`
+	}
+)
+
+// listParamSeparator separates values of query parameters that accept lists.
+const listParamSeparator = ","
 
+// searchUsersHandler searches users by first and last name prefixes if any of them is passed,
+// otherwise by a free-form query and filters.
 func (s *server) searchUsersHandler(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+	if !queryParams.Has("first_name") && !queryParams.Has("last_name") {
+		s.searchUsersByQuery(w, r)
+		return
+	}
+
 	var (
-		queryParams    = r.URL.Query()
 		firstName      = queryParams.Get("first_name")
 		lastName       = queryParams.Get("last_name")
 		limit, _       = strconv.ParseUint(queryParams.Get("limit"), 10, 64)
@@ -45,27 +76,153 @@ func (s *server) searchUsersHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	render.Status(r, http.StatusOK)
-	render.JSON(w, r, s.fillSearchUsersResponse(res))
+	s.renderSearchUsersResponse(w, r, &searchUsersResponse{
+		HasNext: res.HasNext,
+	}, res.Items)
+}
+
+// searchUsersByQuery searches users by the q parameter and the filters,
+// results are sorted by relevance and paginated with an opaque cursor.
+func (s *server) searchUsersByQuery(w http.ResponseWriter, r *http.Request) {
+	serviceRequest, err := getSearchRequest(r.URL.Query())
+	if err != nil {
+		s.renderError(w, r, common.NewError(common.ErrStatusBadRequest, err))
+		return
+	}
+
+	res, err := s.userService.Search(r.Context(), serviceRequest)
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to search users: %w", err)))
+		}
+
+		return
+	}
+
+	s.renderSearchUsersResponse(w, r, &searchUsersResponse{
+		HasNext:    res.HasNext,
+		NextCursor: res.NextCursor,
+		Facets:     getSearchFacetsModel(res.Facets),
+	}, res.Items)
+}
+
+// getSearchRequest parses the query and the filters.
+// List parameters can be repeated or contain comma-separated values.
+func getSearchRequest(queryParams url.Values) (*user_service.SearchRequest, error) {
+	limit, _ := strconv.ParseUint(queryParams.Get("limit"), 10, 64)
+
+	req := &user_service.SearchRequest{
+		Query:            queryParams.Get("q"),
+		Gender:           user_service.GenderType(queryParams.Get("gender")),
+		InterestKeywords: getListParam(queryParams, "interests"),
+		Limit:            limit,
+		Cursor:           queryParams.Get("cursor"),
+	}
+
+	for _, v := range getListParam(queryParams, "city_id") {
+		cityID, err := strconv.ParseInt(v, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse city ID: %w", err)
+		}
+
+		req.CityIDs = append(req.CityIDs, int16(cityID))
+	}
+
+	if v := queryParams.Get("min_age"); v != "" {
+		minAge, err := strconv.ParseUint(v, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse min age: %w", err)
+		}
+
+		req.MinAge = uint8(minAge)
+	}
+
+	if v := queryParams.Get("max_age"); v != "" {
+		maxAge, err := strconv.ParseUint(v, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse max age: %w", err)
+		}
+
+		req.MaxAge = uint8(maxAge)
+	}
+
+	return req, nil
+}
+
+// getListParam returns all non-empty values of the query parameter.
+func getListParam(queryParams url.Values, key string) []string {
+	var result []string
+
+	for _, param := range queryParams[key] {
+		for _, v := range strings.Split(param, listParamSeparator) {
+			if v = strings.TrimSpace(v); v != "" {
+				result = append(result, v)
+			}
+		}
+	}
+
+	return result
 }
 
-func (s *server) fillSearchUsersResponse(res *user_service.SearchByNamePrefixesResponse) *searchUsersResponse {
-	if res == nil {
+func getSearchFacetsModel(facets *user_service.SearchFacets) *searchFacets {
+	if facets == nil {
 		return nil
 	}
 
-	items := make([]*User, 0, len(res.Items))
+	result := &searchFacets{
+		Cities:  make([]*cityFacet, 0, len(facets.Cities)),
+		Genders: make([]*genderFacet, 0, len(facets.Genders)),
+	}
+
+	for _, v := range facets.Cities {
+		result.Cities = append(result.Cities, &cityFacet{
+			CityID: v.CityID,
+			Count:  v.Count,
+		})
+	}
 
-	for _, v := range res.Items {
+	for _, v := range facets.Genders {
+		result.Genders = append(result.Genders, &genderFacet{
+			Gender: string(v.Gender),
+			Count:  v.Count,
+		})
+	}
+
+	return result
+}
+
+// renderSearchUsersResponse fills the response with the users projected for the current viewer and renders it.
+func (s *server) renderSearchUsersResponse(w http.ResponseWriter,
+	r *http.Request,
+	resp *searchUsersResponse,
+	users []*user_service.User) {
+	viewerTypes, err := s.getViewerTypes(r.Context(), users)
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to check who can see users info: %w", err)))
+		}
+
+		return
+	}
+
+	resp.Items = make([]*User, 0, len(users))
+
+	for _, v := range users {
 		if v == nil {
 			continue
 		}
 
-		items = append(items, s.getUserModel(v))
+		resp.Items = append(resp.Items, s.projectUser(v, viewerTypes[v.ID]))
 	}
 
-	return &searchUsersResponse{
-		Items:   items,
-		HasNext: res.HasNext,
-	}
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, resp)
 }
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+	dialog_service "github.com/oshokin/hive-backend/internal/service/dialog"
+)
+
+type (
+	sendMessageRequest struct {
+		Text string `json:"text"`
+	}
+
+	sendMessageResponse struct {
+		MessageID int64 `json:"message_id"`
+	}
+)
+
+func (s *server) sendMessageHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	receiverID, err := strconv.ParseInt(chi.URLParam(r, "user_id"), 10, 64)
+	if err != nil {
+		s.renderError(w, r,
+			common.NewError(common.ErrStatusBadRequest,
+				fmt.Errorf("failed to parse user ID: %w", err)))
+
+		return
+	}
+
+	var req sendMessageRequest
+
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.renderError(w, r,
+			common.NewError(common.ErrStatusBadRequest,
+				fmt.Errorf("failed to decode request: %w", err)))
+
+		return
+	}
+
+	messageID, err := s.dialogService.Send(ctx, &dialog_service.Message{
+		SenderID:   userID,
+		ReceiverID: receiverID,
+		Text:       req.Text,
+	})
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to send message: %w", err)))
+		}
+
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, &sendMessageResponse{
+		MessageID: messageID,
+	})
+}
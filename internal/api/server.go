@@ -11,11 +11,18 @@ import (
 	"github.com/oshokin/hive-backend/internal/common"
 	"github.com/oshokin/hive-backend/internal/config"
 	"github.com/oshokin/hive-backend/internal/logger"
+	session_repo "github.com/oshokin/hive-backend/internal/repository/session"
 	city_service "github.com/oshokin/hive-backend/internal/service/city"
+	counter_service "github.com/oshokin/hive-backend/internal/service/counter"
+	dialog_service "github.com/oshokin/hive-backend/internal/service/dialog"
+	feed_service "github.com/oshokin/hive-backend/internal/service/feed"
+	friend_service "github.com/oshokin/hive-backend/internal/service/friend"
+	post_service "github.com/oshokin/hive-backend/internal/service/post"
 	randomizing_job_service "github.com/oshokin/hive-backend/internal/service/randomizing_job"
 	user_service "github.com/oshokin/hive-backend/internal/service/user"
 	chi_prometheus "github.com/oshokin/hive-backend/internal/util/chi-prometheus"
-	go_cache "github.com/patrickmn/go-cache"
+	"github.com/oshokin/hive-backend/internal/util/keyring"
+	"github.com/oshokin/hive-backend/internal/util/pubsub"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -26,13 +33,22 @@ type Server interface {
 }
 
 type server struct {
+	ctx                   context.Context
 	server                *http.Server
 	router                chi.Router
 	userService           user_service.Service
 	cityService           city_service.Service
 	randomizingJobService randomizing_job_service.Service
-	cache                 *go_cache.Cache
-	jwtSecretKey          []byte
+	friendService         friend_service.Service
+	postService           post_service.Service
+	feedService           feed_service.Service
+	dialogService         dialog_service.Service
+	counterService        counter_service.Service
+	createdPosts          *pubsub.Bus[*post_service.Post]
+	stopDispatchingPosts  func()
+	wsRegistry            *wsRegistry
+	sessionStore          session_repo.SessionStore
+	jwtKeyring            *keyring.Keyring
 }
 
 const (
@@ -44,6 +60,14 @@ const (
 func NewServer(userService user_service.Service,
 	cityService city_service.Service,
 	randomizingJobService randomizing_job_service.Service,
+	friendService friend_service.Service,
+	postService post_service.Service,
+	feedService feed_service.Service,
+	dialogService dialog_service.Service,
+	counterService counter_service.Service,
+	createdPosts *pubsub.Bus[*post_service.Post],
+	sessionStore session_repo.SessionStore,
+	jwtKeyring *keyring.Keyring,
 	config *config.Configuration) Server {
 	r := chi.NewRouter()
 	s := &server{
@@ -51,8 +75,15 @@ func NewServer(userService user_service.Service,
 		userService:           userService,
 		cityService:           cityService,
 		randomizingJobService: randomizingJobService,
-		cache:                 go_cache.New(cacheExpirationTime, cacheCleanupInterval),
-		jwtSecretKey:          config.JWTSecretKey,
+		friendService:         friendService,
+		postService:           postService,
+		feedService:           feedService,
+		dialogService:         dialogService,
+		counterService:        counterService,
+		createdPosts:          createdPosts,
+		wsRegistry:            newWSRegistry(),
+		sessionStore:          sessionStore,
+		jwtKeyring:            jwtKeyring,
 	}
 
 	r.Use(
@@ -60,24 +91,57 @@ func NewServer(userService user_service.Service,
 		middleware.RequestID,
 		middleware.Recoverer,
 		middleware.Heartbeat("/ping"),
-		middleware.Timeout(config.RequestTimeout))
+		middleware.Timeout(config.RequestTimeout),
+		s.consistencyMiddleware)
+
+	requireAdmin := s.requireRole(user_service.RoleAdmin)
 
 	r.Handle("/metrics", promhttp.Handler())
+	r.Get("/.well-known/jwks.json", s.getJWKSHandler)
 	r.Get("/v1/city/list", s.getCitiesHandler)
-	r.Get("/v1/randomizing-job/list", s.getRandomizingJobsHandler)
-	r.Post("/v1/randomizing-job/create", s.createRandomizingJobHandler)
-	r.Post("/v1/randomizing-job/cancel", s.cancelRandomizingJobHandler)
+	r.With(s.authMiddleware).Post("/v1/dialog/{user_id}/send", s.sendMessageHandler)
+	r.With(s.authMiddleware).Get("/v1/dialog/{user_id}/list", s.getMessagesHandler)
+	r.With(s.authMiddleware).Post("/v1/dialog/{user_id}/read", s.readMessagesHandler)
+	r.With(s.authMiddleware).Get("/v1/dialog/unread", s.getUnreadCountersHandler)
+	r.With(s.authMiddleware).Put("/v1/friend/set/{user_id}", s.setFriendHandler)
+	r.With(s.authMiddleware).Put("/v1/friend/delete/{user_id}", s.deleteFriendHandler)
+	r.With(s.authMiddleware).Get("/v1/friend/list", s.getFriendsHandler)
+	r.With(s.authMiddleware).Post("/v1/post/create", s.createPostHandler)
+	r.With(s.authMiddleware).Put("/v1/post/update", s.updatePostHandler)
+	r.With(s.authMiddleware).Put("/v1/post/delete/{id}", s.deletePostHandler)
+	r.With(s.authMiddleware).Get("/v1/post/get/{id}", s.getPostHandler)
+	r.With(s.authMiddleware).Get("/v1/post/feed", s.getFeedHandler)
+	r.With(s.authMiddleware).Get("/v1/post/feed/posted", s.feedPostedHandler)
+	r.With(s.authMiddleware, requireAdmin).Get("/v1/randomizing-job/list", s.getRandomizingJobsHandler)
+	r.With(s.authMiddleware, requireAdmin).Post("/v1/randomizing-job/create", s.createRandomizingJobHandler)
+	r.With(s.authMiddleware, requireAdmin).Post("/v1/randomizing-job/cancel", s.cancelRandomizingJobHandler)
 	r.Post("/v1/user/create", s.createUserHandler)
 	r.Post("/v1/user/login", s.loginUserHandler)
+	r.Post("/v1/user/refresh", s.refreshUserTokenHandler)
 	r.With(s.authMiddleware).Post("/v1/user/logout", s.logoutUserHandler)
-	r.Get("/v1/user/{id}", s.getUserHandler)
-	r.Get("/v1/user/search", s.searchUsersHandler)
+	r.With(s.authMiddleware).Post("/v1/user/password/change", s.changePasswordHandler)
+	r.Post("/v1/user/password/reset/request", s.requestPasswordResetHandler)
+	r.Post("/v1/user/password/reset/confirm", s.confirmPasswordResetHandler)
+	r.With(s.authMiddleware).Get("/v1/user/sessions", s.getSessionsHandler)
+	r.With(s.authMiddleware).Post("/v1/user/sessions/revoke", s.revokeSessionsHandler)
+	r.With(s.authMiddleware).Get("/v1/user/me", s.getCurrentUserHandler)
+	r.With(s.authMiddleware).Patch("/v1/user/me", s.updateCurrentUserHandler)
+	r.With(s.authMiddleware).Delete("/v1/user/me", s.deleteCurrentUserHandler)
+	r.With(s.optionalAuthMiddleware).Get("/v1/user/{id}", s.getUserHandler)
+	r.With(s.optionalAuthMiddleware).Get("/v1/user/search", s.searchUsersHandler)
 
 	return s
 }
 
 // Start starts the HTTP server on the specified port.
 func (s *server) Start(ctx context.Context, port uint16) {
+	s.ctx = ctx
+
+	createdPosts, unsubscribe := s.createdPosts.Subscribe()
+	s.stopDispatchingPosts = unsubscribe
+
+	go s.dispatchCreatedPosts(ctx, createdPosts)
+
 	s.server = &http.Server{
 		Addr:              fmt.Sprintf(":%d", port),
 		Handler:           s.router,
@@ -106,5 +170,12 @@ func (s *server) Stop(ctx context.Context) {
 		logger.ErrorKV(ctx, "failed to stop server", common.ErrorTag, err)
 	}
 
+	// Hijacked connections aren't tracked by the HTTP server, so they are closed separately.
+	if s.stopDispatchingPosts != nil {
+		s.stopDispatchingPosts()
+	}
+
+	s.wsRegistry.closeAll()
+
 	logger.Info(ctx, "server stopped")
 }
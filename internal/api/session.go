@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/oshokin/hive-backend/internal/common"
+	"github.com/oshokin/hive-backend/internal/logger"
+	session_repo "github.com/oshokin/hive-backend/internal/repository/session"
+)
+
+const (
+	randomIDLength         = 16
+	lastSeenUpdateInterval = 1 * time.Minute
+)
+
+// newSession creates a session of a new refresh token of the family issued in response to the request.
+func newSession(r *http.Request, userID int64, familyID string) (*session_repo.Session, error) {
+	id, err := generateRandomID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	return &session_repo.Session{
+		ID:         id,
+		FamilyID:   familyID,
+		UserID:     userID,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(refreshTokenDuration),
+		LastSeenAt: now,
+		UserAgent:  r.UserAgent(),
+		IP:         getClientIP(r),
+	}, nil
+}
+
+// touchSession updates the last request time of the session,
+// it's done not more often than once in lastSeenUpdateInterval to spare the master.
+func (s *server) touchSession(ctx context.Context, session *session_repo.Session) {
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) < lastSeenUpdateInterval {
+		return
+	}
+
+	if err := s.sessionStore.Touch(ctx, session.ID, now); err != nil {
+		logger.ErrorKV(ctx, "failed to update last request time of session",
+			common.UserIDTag, session.UserID,
+			common.ErrorTag, err)
+
+		return
+	}
+
+	session.LastSeenAt = now
+}
+
+func getClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func generateRandomID() (string, error) {
+	b := make([]byte, randomIDLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+	user_service "github.com/oshokin/hive-backend/internal/service/user"
+)
+
+type (
+	// updateCurrentUserRequest contains the profile fields to update.
+	// Fields that are absent in the request body are left unchanged.
+	updateCurrentUserRequest struct {
+		CityID    *int16                `json:"city_id"`
+		FirstName *string               `json:"first_name"`
+		LastName  *string               `json:"last_name"`
+		Birthdate *dateOnly             `json:"birthdate"`
+		Gender    *string               `json:"gender"`
+		Interests *string               `json:"interests"`
+		Privacy   *updatePrivacyRequest `json:"privacy"`
+	}
+
+	// updatePrivacyRequest contains the privacy settings to update.
+	updatePrivacyRequest struct {
+		Email     *string `json:"email"`
+		Birthdate *string `json:"birthdate"`
+		Interests *string `json:"interests"`
+	}
+)
+
+// updateCurrentUserHandler updates the given profile fields of the current user
+// and returns the updated profile.
+func (s *server) updateCurrentUserHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	var req updateCurrentUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.renderError(w, r,
+			common.NewError(common.ErrStatusBadRequest,
+				fmt.Errorf("failed to decode request: %w", err)))
+
+		return
+	}
+
+	user, err := s.userService.Update(ctx, req.getServiceModel(userID))
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to update user info: %w", err)))
+		}
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, s.projectUser(user, viewerOwner))
+}
+
+func (req *updateCurrentUserRequest) getServiceModel(userID int64) *user_service.UpdateRequest {
+	var (
+		u = &user_service.User{
+			ID: userID,
+		}
+		fields = new(user_service.UpdateFields)
+	)
+
+	if req.CityID != nil {
+		u.CityID = *req.CityID
+		fields.CityID = true
+	}
+
+	if req.FirstName != nil {
+		u.FirstName = *req.FirstName
+		fields.FirstName = true
+	}
+
+	if req.LastName != nil {
+		u.LastName = *req.LastName
+		fields.LastName = true
+	}
+
+	if req.Birthdate != nil {
+		u.Birthdate = time.Time(*req.Birthdate)
+		fields.Birthdate = true
+	}
+
+	if req.Gender != nil {
+		u.Gender = user_service.GenderType(*req.Gender)
+		fields.Gender = true
+	}
+
+	if req.Interests != nil {
+		u.Interests = *req.Interests
+		fields.Interests = true
+	}
+
+	if p := req.Privacy; p != nil {
+		if p.Email != nil {
+			u.EmailVisibility = user_service.VisibilityType(*p.Email)
+			fields.EmailVisibility = true
+		}
+
+		if p.Birthdate != nil {
+			u.BirthdateVisibility = user_service.VisibilityType(*p.Birthdate)
+			fields.BirthdateVisibility = true
+		}
+
+		if p.Interests != nil {
+			u.InterestsVisibility = user_service.VisibilityType(*p.Interests)
+			fields.InterestsVisibility = true
+		}
+	}
+
+	return &user_service.UpdateRequest{
+		User:   u,
+		Fields: fields,
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/oshokin/hive-backend/internal/service/common"
+	post_service "github.com/oshokin/hive-backend/internal/service/post"
+)
+
+type (
+	updatePostRequest struct {
+		ID   int64  `json:"id"`
+		Text string `json:"text"`
+	}
+
+	updatePostResponse struct {
+		Success bool `json:"success"`
+	}
+)
+
+func (s *server) updatePostHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID := getUserIDFromContext(ctx)
+	if userID == 0 {
+		s.renderError(w, r, errAccessDenied)
+		return
+	}
+
+	var (
+		req updatePostRequest
+		err = json.NewDecoder(r.Body).Decode(&req)
+	)
+
+	if err != nil {
+		s.renderError(w, r,
+			common.NewError(common.ErrStatusBadRequest,
+				fmt.Errorf("failed to decode request: %w", err)))
+
+		return
+	}
+
+	err = s.postService.Update(ctx, userID, &post_service.Post{
+		ID:   req.ID,
+		Text: req.Text,
+	})
+	if err != nil {
+		var e *common.Error
+		if errors.As(err, &e) {
+			s.renderError(w, r, e)
+		} else {
+			s.renderError(w, r, common.NewError(common.ErrStatusInternalError,
+				fmt.Errorf("failed to update post: %w", err)))
+		}
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &updatePostResponse{
+		Success: true,
+	})
+}
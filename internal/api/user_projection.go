@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	user_service "github.com/oshokin/hive-backend/internal/service/user"
+)
+
+// viewerType describes the relation of the viewer of a user profile to its owner.
+type viewerType uint8
+
+const (
+	viewerAnonymous viewerType = iota // The viewer isn't authenticated.
+	viewerStranger                    // The viewer is authenticated, but isn't in the owner's friend list.
+	viewerFriend                      // The viewer is in the owner's friend list.
+	viewerOwner                       // The viewer is the owner of the profile.
+)
+
+// canSee reports whether the viewer can see a profile field with the given visibility.
+func (v viewerType) canSee(visibility user_service.VisibilityType) bool {
+	switch visibility {
+	case user_service.VisibilityPublic:
+		return true
+	case user_service.VisibilityFriends:
+		return v >= viewerFriend
+	default:
+		return v == viewerOwner
+	}
+}
+
+// getViewerTypes returns the relation of the current user to each of the given users.
+func (s *server) getViewerTypes(ctx context.Context,
+	users []*user_service.User) (map[int64]viewerType, error) {
+	var (
+		viewerID     = getUserIDFromContext(ctx)
+		result       = make(map[int64]viewerType, len(users))
+		candidateIDs = make([]int64, 0, len(users))
+	)
+
+	for _, v := range users {
+		switch {
+		case viewerID == 0:
+			result[v.ID] = viewerAnonymous
+		case v.ID == viewerID:
+			result[v.ID] = viewerOwner
+		default:
+			result[v.ID] = viewerStranger
+			candidateIDs = append(candidateIDs, v.ID)
+		}
+	}
+
+	if len(candidateIDs) == 0 {
+		return result, nil
+	}
+
+	followerIDs, err := s.friendService.GetFollowerIDsAmong(ctx, viewerID, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for id := range followerIDs {
+		result[id] = viewerFriend
+	}
+
+	return result, nil
+}
+
+// projectUser returns the API model of the user with only the fields visible to the viewer.
+// Privacy settings are returned to the owner only.
+func (s *server) projectUser(user *user_service.User, viewer viewerType) *User {
+	if user == nil {
+		return nil
+	}
+
+	result := &User{
+		ID:        user.ID,
+		CityID:    user.CityID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Gender:    string(user.Gender),
+	}
+
+	if viewer.canSee(user.EmailVisibility) {
+		result.Email = user.Email
+	}
+
+	if viewer.canSee(user.BirthdateVisibility) {
+		result.Birthdate = user.Birthdate.Format(time.DateOnly)
+	}
+
+	if viewer.canSee(user.InterestsVisibility) {
+		result.Interests = user.Interests
+	}
+
+	if viewer == viewerOwner {
+		result.Privacy = &UserPrivacy{
+			Email:     string(user.EmailVisibility),
+			Birthdate: string(user.BirthdateVisibility),
+			Interests: string(user.InterestsVisibility),
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	friend_service "github.com/oshokin/hive-backend/internal/service/friend"
+	user_service "github.com/oshokin/hive-backend/internal/service/user"
+)
+
+// stubFriendService returns the configured followers of the viewer,
+// other methods aren't used by the projection and panic if called.
+type stubFriendService struct {
+	friend_service.Service
+	followerIDs map[int64][]int64
+}
+
+func (s *stubFriendService) GetFollowerIDsAmong(_ context.Context,
+	userID int64,
+	candidateIDs []int64) (map[int64]struct{}, error) {
+	candidates := make(map[int64]struct{}, len(candidateIDs))
+	for _, v := range candidateIDs {
+		candidates[v] = struct{}{}
+	}
+
+	result := make(map[int64]struct{})
+
+	for _, v := range s.followerIDs[userID] {
+		if _, ok := candidates[v]; ok {
+			result[v] = struct{}{}
+		}
+	}
+
+	return result, nil
+}
+
+var allViewerTypes = []viewerType{viewerAnonymous, viewerStranger, viewerFriend, viewerOwner}
+
+// visibilityTests lists the viewers that can see a field with each visibility.
+var visibilityTests = []struct {
+	visibility user_service.VisibilityType
+	visibleTo  map[viewerType]bool
+}{
+	{
+		visibility: user_service.VisibilityPublic,
+		visibleTo: map[viewerType]bool{
+			viewerAnonymous: true,
+			viewerStranger:  true,
+			viewerFriend:    true,
+			viewerOwner:     true,
+		},
+	},
+	{
+		visibility: user_service.VisibilityFriends,
+		visibleTo: map[viewerType]bool{
+			viewerFriend: true,
+			viewerOwner:  true,
+		},
+	},
+	{
+		visibility: user_service.VisibilityOnlyMe,
+		visibleTo: map[viewerType]bool{
+			viewerOwner: true,
+		},
+	},
+}
+
+func TestViewerTypeCanSee(t *testing.T) {
+	for _, tt := range visibilityTests {
+		for _, viewer := range allViewerTypes {
+			if got, want := viewer.canSee(tt.visibility), tt.visibleTo[viewer]; got != want {
+				t.Errorf("viewer %d, visibility %s: canSee() = %t, want %t", viewer, tt.visibility, got, want)
+			}
+		}
+	}
+}
+
+func TestProjectUser(t *testing.T) {
+	var (
+		s         = &server{}
+		birthdate = time.Date(1990, time.May, 17, 0, 0, 0, 0, time.UTC)
+	)
+
+	for _, tt := range visibilityTests {
+		user := &user_service.User{
+			ID:                  1,
+			Email:               "user@example.com",
+			CityID:              2,
+			FirstName:           "Ivan",
+			LastName:            "Ivanov",
+			Birthdate:           birthdate,
+			Gender:              user_service.GenderMale,
+			Interests:           "chess",
+			EmailVisibility:     tt.visibility,
+			BirthdateVisibility: tt.visibility,
+			InterestsVisibility: tt.visibility,
+		}
+
+		for _, viewer := range allViewerTypes {
+			got := s.projectUser(user, viewer)
+			if got.ID != user.ID || got.FirstName != user.FirstName || got.LastName != user.LastName {
+				t.Errorf("viewer %d, visibility %s: public fields are missing: %+v", viewer, tt.visibility, got)
+			}
+
+			isVisible := tt.visibleTo[viewer]
+			if (got.Email != "") != isVisible {
+				t.Errorf("viewer %d, visibility %s: email = %q, want visible %t",
+					viewer, tt.visibility, got.Email, isVisible)
+			}
+
+			if (got.Birthdate != "") != isVisible {
+				t.Errorf("viewer %d, visibility %s: birthdate = %q, want visible %t",
+					viewer, tt.visibility, got.Birthdate, isVisible)
+			}
+
+			if (got.Interests != "") != isVisible {
+				t.Errorf("viewer %d, visibility %s: interests = %q, want visible %t",
+					viewer, tt.visibility, got.Interests, isVisible)
+			}
+
+			if (got.Privacy != nil) != (viewer == viewerOwner) {
+				t.Errorf("viewer %d, visibility %s: privacy = %+v, want it for the owner only",
+					viewer, tt.visibility, got.Privacy)
+			}
+		}
+	}
+}
+
+func TestGetViewerTypes(t *testing.T) {
+	const (
+		viewerID   = int64(1)
+		followerID = int64(2)
+		strangerID = int64(3)
+	)
+
+	var (
+		s = &server{
+			friendService: &stubFriendService{
+				followerIDs: map[int64][]int64{viewerID: {followerID}},
+			},
+		}
+		users = []*user_service.User{{ID: viewerID}, {ID: followerID}, {ID: strangerID}}
+	)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want map[int64]viewerType
+	}{
+		{
+			name: "anonymous",
+			ctx:  context.Background(),
+			want: map[int64]viewerType{
+				viewerID:   viewerAnonymous,
+				followerID: viewerAnonymous,
+				strangerID: viewerAnonymous,
+			},
+		},
+		{
+			name: "authenticated",
+			ctx:  context.WithValue(context.Background(), userIDHeader, viewerID),
+			want: map[int64]viewerType{
+				viewerID:   viewerOwner,
+				followerID: viewerFriend,
+				strangerID: viewerStranger,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.getViewerTypes(tt.ctx, users)
+			if err != nil {
+				t.Fatalf("getViewerTypes() error = %v", err)
+			}
+
+			for id, want := range tt.want {
+				if got[id] != want {
+					t.Errorf("user %d: viewer type = %d, want %d", id, got[id], want)
+				}
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/render"
 	"github.com/oshokin/hive-backend/internal/logger"
@@ -13,7 +15,10 @@ type apiError struct {
 	Message string `json:"message"`
 }
 
-const clientErrorsClass = 4
+const (
+	clientErrorsClass = 4
+	retryAfterHeader  = "Retry-After"
+)
 
 func (s *server) renderError(w http.ResponseWriter, r *http.Request, err *common.Error) {
 	var (
@@ -30,6 +35,11 @@ func (s *server) renderError(w http.ResponseWriter, r *http.Request, err *common
 		logger.Error(ctx, errMessage)
 	}
 
+	if retryAfter := err.RetryAfter; retryAfter > 0 {
+		seconds := int64(math.Ceil(retryAfter.Seconds()))
+		w.Header().Set(retryAfterHeader, strconv.FormatInt(seconds, 10))
+	}
+
 	render.Status(r, errType.HTTPStatus())
 	render.JSON(w, r, &apiError{
 		Code:    errType.String(),
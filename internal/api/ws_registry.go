@@ -0,0 +1,194 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/oshokin/hive-backend/internal/common"
+	"github.com/oshokin/hive-backend/internal/logger"
+)
+
+type (
+	// wsRegistry keeps open WebSocket connections grouped by user ID.
+	wsRegistry struct {
+		connections map[int64]map[*wsConnection]struct{}
+		mu          sync.RWMutex
+	}
+
+	// wsConnection is a WebSocket connection of a single user.
+	// Messages are written by a dedicated goroutine reading the send channel.
+	wsConnection struct {
+		userID    int64
+		conn      *websocket.Conn
+		send      chan any
+		done      chan struct{}
+		closeOnce sync.Once
+	}
+)
+
+const (
+	wsWriteTimeout     = 10 * time.Second
+	wsPongTimeout      = 60 * time.Second
+	wsPingInterval     = wsPongTimeout * 9 / 10
+	wsMaxMessageSize   = 512
+	wsSendBufferLength = 32
+)
+
+func newWSRegistry() *wsRegistry {
+	return &wsRegistry{
+		connections: make(map[int64]map[*wsConnection]struct{}),
+	}
+}
+
+func newWSConnection(userID int64, conn *websocket.Conn) *wsConnection {
+	return &wsConnection{
+		userID: userID,
+		conn:   conn,
+		send:   make(chan any, wsSendBufferLength),
+		done:   make(chan struct{}),
+	}
+}
+
+// add registers the connection and starts serving it until it's closed.
+func (reg *wsRegistry) add(ctx context.Context, c *wsConnection) {
+	reg.mu.Lock()
+
+	userConnections, ok := reg.connections[c.userID]
+	if !ok {
+		userConnections = make(map[*wsConnection]struct{})
+		reg.connections[c.userID] = userConnections
+	}
+
+	userConnections[c] = struct{}{}
+	reg.mu.Unlock()
+
+	go c.writePump(ctx)
+	go func() {
+		c.readPump()
+		reg.remove(c)
+	}()
+}
+
+func (reg *wsRegistry) remove(c *wsConnection) {
+	reg.mu.Lock()
+
+	if userConnections, ok := reg.connections[c.userID]; ok {
+		delete(userConnections, c)
+
+		if len(userConnections) == 0 {
+			delete(reg.connections, c.userID)
+		}
+	}
+
+	reg.mu.Unlock()
+	c.close()
+}
+
+// sendToUsers queues the message for all connections of the given users.
+// Connections that can't keep up are closed.
+func (reg *wsRegistry) sendToUsers(ctx context.Context, userIDs []int64, message any) {
+	var slowConnections []*wsConnection
+
+	reg.mu.RLock()
+
+	for _, userID := range userIDs {
+		for c := range reg.connections[userID] {
+			select {
+			case c.send <- message:
+			default:
+				slowConnections = append(slowConnections, c)
+			}
+		}
+	}
+
+	reg.mu.RUnlock()
+
+	for _, c := range slowConnections {
+		logger.WarnKV(ctx, "closing slow websocket connection", common.UserIDTag, c.userID)
+		reg.remove(c)
+	}
+}
+
+// closeAll closes all registered connections.
+func (reg *wsRegistry) closeAll() {
+	reg.mu.Lock()
+
+	var connections []*wsConnection
+
+	for userID, userConnections := range reg.connections {
+		for c := range userConnections {
+			connections = append(connections, c)
+		}
+
+		delete(reg.connections, userID)
+	}
+
+	reg.mu.Unlock()
+
+	for _, c := range connections {
+		c.close()
+	}
+}
+
+func (c *wsConnection) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *wsConnection) readPump() {
+	defer c.close()
+
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	// Clients aren't expected to send anything, reading is needed to process control messages.
+	for {
+		if _, _, err := c.conn.NextReader(); err != nil {
+			return
+		}
+	}
+}
+
+func (c *wsConnection) writePump(ctx context.Context) {
+	ticker := time.NewTicker(wsPingInterval)
+
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			_ = c.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, ""),
+				time.Now().Add(wsWriteTimeout))
+
+			return
+		case message := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+
+			if err := c.conn.WriteJSON(message); err != nil {
+				logger.WarnKV(ctx, "failed to write to websocket",
+					common.UserIDTag, c.userID,
+					common.ErrorTag, err)
+				c.close()
+
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
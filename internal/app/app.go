@@ -11,12 +11,30 @@ import (
 	"github.com/oshokin/hive-backend/internal/config"
 	"github.com/oshokin/hive-backend/internal/db"
 	"github.com/oshokin/hive-backend/internal/logger"
+	"github.com/oshokin/hive-backend/internal/notifier"
 	city_repo "github.com/oshokin/hive-backend/internal/repository/city"
+	counter_repo "github.com/oshokin/hive-backend/internal/repository/counter"
+	dialog_repo "github.com/oshokin/hive-backend/internal/repository/dialog"
+	friend_repo "github.com/oshokin/hive-backend/internal/repository/friend"
+	login_attempt_repo "github.com/oshokin/hive-backend/internal/repository/login_attempt"
+	password_reset_repo "github.com/oshokin/hive-backend/internal/repository/password_reset"
+	post_repo "github.com/oshokin/hive-backend/internal/repository/post"
 	randomizing_job_repo "github.com/oshokin/hive-backend/internal/repository/randomizing_job"
+	session_repo "github.com/oshokin/hive-backend/internal/repository/session"
 	user_repo "github.com/oshokin/hive-backend/internal/repository/user"
 	city_service "github.com/oshokin/hive-backend/internal/service/city"
+	counter_service "github.com/oshokin/hive-backend/internal/service/counter"
+	dialog_service "github.com/oshokin/hive-backend/internal/service/dialog"
+	feed_service "github.com/oshokin/hive-backend/internal/service/feed"
+	friend_service "github.com/oshokin/hive-backend/internal/service/friend"
+	login_limiter_service "github.com/oshokin/hive-backend/internal/service/login_limiter"
+	post_service "github.com/oshokin/hive-backend/internal/service/post"
 	randomizing_job_service "github.com/oshokin/hive-backend/internal/service/randomizing_job"
 	user_service "github.com/oshokin/hive-backend/internal/service/user"
+	user_purger_service "github.com/oshokin/hive-backend/internal/service/user_purger"
+	"github.com/oshokin/hive-backend/internal/util/keyring"
+	password_hasher "github.com/oshokin/hive-backend/internal/util/password_hasher"
+	"github.com/oshokin/hive-backend/internal/util/pubsub"
 )
 
 // Application represents the main application struct.
@@ -26,12 +44,33 @@ type Application struct {
 	cityRepo              city_repo.Repository            // Repository for managing city data
 	cityService           city_service.Service            // Service for managing city data
 	userRepo              user_repo.Repository            // Repository for managing user data
+	passwordResetRepo     password_reset_repo.Repository  // Repository for managing password reset tokens
+	loginAttemptRepo      login_attempt_repo.Repository   // Repository for managing failed login attempts
+	loginLimiterService   login_limiter_service.Service   // Service for throttling login attempts
+	notifier              notifier.Notifier               // Notifier for delivering messages to users
 	userService           user_service.Service            // Service for managing user data
+	userPurgerService     user_purger_service.Service     // Service for purging deleted users
 	randomizingJobRepo    randomizing_job_repo.Repository // Repository for managing user randomizing job data
 	randomizingJobService randomizing_job_service.Service // Service for managing user randomizing job data
+	feedService           feed_service.Service            // Service for managing news feeds of users
+	friendRepo            friend_repo.Repository          // Repository for managing friend lists
+	friendService         friend_service.Service          // Service for managing friend lists
+	postRepo              post_repo.Repository            // Repository for managing post data
+	postService           post_service.Service            // Service for managing post data
+	dialogRepo            dialog_repo.Repository          // Repository for managing private messages
+	dialogService         dialog_service.Service          // Service for managing private messages
+	counterRepo           counter_repo.Repository         // Repository for managing unread message counters
+	counterService        counter_service.Service         // Service for managing unread message counters
+	createdPosts          *pubsub.Bus[*post_service.Post] // Bus for notifying about created posts
+	sessionStore          session_repo.SessionStore       // Storage of refresh token sessions
+	jwtKeyring            *keyring.Keyring                // Keys for signing and verifying JSON Web Tokens
+	passwordHasher        password_hasher.PasswordHasher  // Hasher of user passwords
 	server                api.Server                      // HTTP server for handling API requests
 }
 
+// createdPostsBufferLength is the buffer size of every subscription to created posts.
+const createdPostsBufferLength = 1000
+
 // NewApplication creates a new Application instance with the given context.
 func NewApplication(ctx context.Context) (*Application, error) {
 	var err error
@@ -41,6 +80,16 @@ func NewApplication(ctx context.Context) (*Application, error) {
 		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	jwtKeyring, err := keyring.NewKeyring(config.JWTKeyring)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+
+	passwordHasher, err := password_hasher.NewPasswordHasher(config.PasswordHasher)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create password hasher: %w", err)
+	}
+
 	dbCluster, err := db.NewCluster(ctx, config.DBClusterConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -51,12 +100,42 @@ func NewApplication(ctx context.Context) (*Application, error) {
 	cityRepo := city_repo.NewRepository(dbCluster)
 	cityService := city_service.NewService(cityRepo)
 	userRepo := user_repo.NewRepository(dbCluster)
-	userService := user_service.NewService(userRepo, cityService, config.FakeUserPassword)
+	passwordResetRepo := password_reset_repo.NewRepository(dbCluster)
+	notifier := newNotifier(config)
+	loginAttemptRepo := newLoginAttemptRepo(config, dbCluster)
+	loginLimiterService := login_limiter_service.NewService(loginAttemptRepo)
+	userService := user_service.NewService(userRepo,
+		passwordResetRepo,
+		cityService,
+		loginLimiterService,
+		notifier,
+		passwordHasher,
+		config.FakeUserPassword)
+	userPurgerService := user_purger_service.NewService(userRepo, config.UserRetention)
 	randomizingJobRepo := randomizing_job_repo.NewRepository(dbCluster)
 	randomizingJobService := randomizing_job_service.NewService(randomizingJobRepo, userService)
+	friendRepo := friend_repo.NewRepository(dbCluster)
+	postRepo := post_repo.NewRepository(dbCluster)
+	feedService := feed_service.NewService(friendRepo, postRepo)
+	friendService := friend_service.NewService(friendRepo, userService, feedService)
+	createdPosts := pubsub.NewBus[*post_service.Post](createdPostsBufferLength)
+	postService := post_service.NewService(postRepo, feedService, createdPosts)
+	dialogRepo := dialog_repo.NewRepository(dbCluster)
+	counterRepo := counter_repo.NewRepository(dbCluster)
+	counterService := counter_service.NewService(counterRepo, dialogRepo)
+	dialogService := dialog_service.NewService(dialogRepo, userService, counterService)
+	sessionStore := newSessionStore(config, dbCluster)
 	server := api.NewServer(userService,
 		cityService,
 		randomizingJobService,
+		friendService,
+		postService,
+		feedService,
+		dialogService,
+		counterService,
+		createdPosts,
+		sessionStore,
+		jwtKeyring,
 		config)
 
 	return &Application{
@@ -65,9 +144,27 @@ func NewApplication(ctx context.Context) (*Application, error) {
 		cityRepo:              cityRepo,
 		cityService:           cityService,
 		userRepo:              userRepo,
+		passwordResetRepo:     passwordResetRepo,
+		loginAttemptRepo:      loginAttemptRepo,
+		loginLimiterService:   loginLimiterService,
+		notifier:              notifier,
 		userService:           userService,
+		userPurgerService:     userPurgerService,
 		randomizingJobRepo:    randomizingJobRepo,
 		randomizingJobService: randomizingJobService,
+		feedService:           feedService,
+		friendRepo:            friendRepo,
+		friendService:         friendService,
+		postRepo:              postRepo,
+		postService:           postService,
+		dialogRepo:            dialogRepo,
+		dialogService:         dialogService,
+		counterRepo:           counterRepo,
+		counterService:        counterService,
+		createdPosts:          createdPosts,
+		sessionStore:          sessionStore,
+		jwtKeyring:            jwtKeyring,
+		passwordHasher:        passwordHasher,
 		server:                server,
 	}, nil
 }
@@ -83,12 +180,49 @@ func (app *Application) Run(ctx context.Context) {
 	defer stopReceivingSignals()
 	defer app.dbCluster.Close()
 
+	app.dbCluster.Start(ctx)
+	app.sessionStore.Start(ctx)
 	app.server.Start(ctx, app.config.ServerPort)
 	app.randomizingJobService.Start(ctx)
+	app.feedService.Start(ctx)
+	app.counterService.Start(ctx)
+	app.loginLimiterService.Start(ctx)
+	app.userPurgerService.Start(ctx)
 
 	<-ctx.Done()
 	stopReceivingSignals()
 
+	app.userPurgerService.Stop(ctx)
+	app.loginLimiterService.Stop(ctx)
+	app.counterService.Stop(ctx)
+	app.feedService.Stop(ctx)
 	app.randomizingJobService.Stop(ctx)
 	app.server.Stop(ctx)
+	app.sessionStore.Stop(ctx)
+	app.dbCluster.Stop(ctx)
+	app.createdPosts.Close()
+}
+
+func newNotifier(c *config.Configuration) notifier.Notifier {
+	if c.NotificationFile != "" {
+		return notifier.NewFileNotifier(c.NotificationFile)
+	}
+
+	return notifier.NewLogNotifier()
+}
+
+func newLoginAttemptRepo(c *config.Configuration, dbCluster *db.Cluster) login_attempt_repo.Repository {
+	if c.LoginAttemptStore == config.StoreMemory {
+		return login_attempt_repo.NewMemoryRepository()
+	}
+
+	return login_attempt_repo.NewRepository(dbCluster)
+}
+
+func newSessionStore(c *config.Configuration, dbCluster *db.Cluster) session_repo.SessionStore {
+	if c.SessionStore == config.StoreMemory {
+		return session_repo.NewMemoryStore()
+	}
+
+	return session_repo.NewPostgresStore(dbCluster)
 }
@@ -2,16 +2,27 @@ package common
 
 // Logger tags ...
 const (
+	ActualCountTag                = "actual_count"
 	AddedUsersCountTag            = "added_users_count"
+	CreatedCountTag               = "created_count"
 	CurrentCountTag               = "current_count"
+	DatabaseTag                   = "database"
+	DroppedCountTag               = "dropped_count"
 	ElapsedTimeTag                = "elapsed_time"
+	EmailTag                      = "email"
 	ErrorTag                      = "error"
 	GenerationElapsedTimeTag      = "generation_elapsed_time"
+	MessageTag                    = "message"
+	PartnerIDTag                  = "partner_id"
+	PostIDTag                     = "post_id"
+	PurgedCountTag                = "purged_count"
 	RandomizingJobIDTag           = "randomizing_job_id"
 	RandomizingJobStatusTag       = "randomizing_job_status"
 	RandomizingJobErrorMessageTag = "randomizing_job_error_message"
+	ReplicationLagTag             = "replication_lag"
 	SavingElapsedTimeTag          = "saving_elapsed_time"
 	TimePerUserTag                = "time_per_user"
+	UserIDTag                     = "user_id"
 	UsersToAddCountTag            = "users_to_add_count"
 	UserTag                       = "user"
 )
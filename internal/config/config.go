@@ -7,35 +7,69 @@ import (
 	"time"
 
 	"github.com/oshokin/hive-backend/internal/db"
+	"github.com/oshokin/hive-backend/internal/util/keyring"
+	password_hasher "github.com/oshokin/hive-backend/internal/util/password_hasher"
 	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Configuration represents the application configuration.
 type Configuration struct {
-	AppName          string                   // Name of the application.
-	LogLevel         string                   // Logging level of the application.
-	ServerPort       uint16                   // Port on which the application listens for requests.
-	RequestTimeout   time.Duration            // Maximum duration for a request to complete before timing out.
-	JWTSecretKey     []byte                   // Secret key used to sign and verify JSON Web Tokens.
-	FakeUserPassword string                   // Password string used for generating random users.
-	DBClusterConfig  *db.ClusterConfiguration // Database cluster configuration.
+	AppName           string                         // Name of the application.
+	LogLevel          string                         // Logging level of the application.
+	ServerPort        uint16                         // Port on which the application listens for requests.
+	RequestTimeout    time.Duration                  // Maximum duration for a request to complete before timing out.
+	JWTKeyring        *keyring.Configuration         // Keys used to sign and verify JSON Web Tokens.
+	FakeUserPassword  string                         // Password string used for generating random users.
+	SessionStore      string                         // Type of the storage of refresh token sessions.
+	LoginAttemptStore string                         // Type of the storage of failed login attempts.
+	NotificationFile  string                         // Path to the file notifications are written to (they're logged if empty).
+	PasswordHasher    *password_hasher.Configuration // Password hashing configuration.
+	UserRetention     time.Duration                  // How long deleted users are kept before they're purged.
+	DBClusterConfig   *db.ClusterConfiguration       // Database cluster configuration.
 }
 
+// Types of storages of the state shared between requests.
+const (
+	StoreMemory   = "memory"   // The state is kept in memory of the process.
+	StorePostgres = "postgres" // The state is kept in the master database and shared between instances.
+)
+
 // Constants with default values used for initialization.
 const (
-	defaultAppName              = "hive-backend"
-	defaultEnvPrefix            = "HIVE_BACKEND"
-	defaultServerPort           = uint16(8080)
-	defaultRequestTimeout       = 5 * time.Second
-	defaultDBMaxConnections     = 100
-	defaultDBConnectionLifetime = 1 * time.Minute
+	defaultAppName               = "hive-backend"
+	defaultEnvPrefix             = "HIVE_BACKEND"
+	defaultServerPort            = uint16(8080)
+	defaultRequestTimeout        = 5 * time.Second
+	defaultSessionStore          = StorePostgres
+	defaultLoginAttemptStore     = StorePostgres
+	defaultJWTSecretKeyID        = "default"
+	defaultPasswordHashAlgorithm = password_hasher.AlgorithmBcrypt
+	defaultBcryptCost            = bcrypt.DefaultCost
+	defaultArgon2Memory          = 64 * 1024
+	defaultArgon2Iterations      = 3
+	defaultArgon2Parallelism     = 2
+	defaultUserRetention         = 30 * 24 * time.Hour
+	jwtKeysSeparator             = ","
+	jwtKeyFieldsSeparator        = ":"
+	jwtKeyFieldsCount            = 3
+	defaultDBMaxConnections      = 100
+	defaultDBConnectionLifetime  = 1 * time.Minute
+	defaultDBMaxReplicationLag   = 5 * time.Second
+	defaultDBNodes               = "master,sync,async"
+	defaultDBMasterName          = "master"
+	defaultDBWeight              = 1
+	defaultDBRoutingStrategy     = db.RoutingRoundRobin
+	dbNodesSeparator             = ","
 )
 
 // Errors that can occur during configuration validation.
 var (
-	errConfigIsEmpty           = errors.New("configuration is empty")
-	errJWTKeyIsEmpty           = errors.New("jwt secret key is empty")
-	errFakeUserPasswordIsEmpty = errors.New("fake user password is empty")
+	errConfigIsEmpty            = errors.New("configuration is empty")
+	errJWTKeyIsEmpty            = errors.New("jwt secret key is empty")
+	errFakeUserPasswordIsEmpty  = errors.New("fake user password is empty")
+	errUnknownSessionStore      = errors.New("unknown session store")
+	errUnknownLoginAttemptStore = errors.New("unknown login attempt store")
 )
 
 // GetDefaults loads configuration from environment variables and returns a pointer to Configuration.
@@ -55,19 +89,114 @@ func GetDefaults() (*Configuration, error) {
 
 func getConfigFromEnvVars() *Configuration {
 	return &Configuration{
-		AppName:          defaultAppName,
-		LogLevel:         viper.GetString("LOG_LEVEL"),
-		ServerPort:       viper.GetUint16("SERVER_PORT"),
-		JWTSecretKey:     []byte(viper.GetString("JWT_SECRET_KEY")),
-		FakeUserPassword: viper.GetString("FAKE_USER_PASSWORD"),
+		AppName:           defaultAppName,
+		LogLevel:          viper.GetString("LOG_LEVEL"),
+		ServerPort:        viper.GetUint16("SERVER_PORT"),
+		JWTKeyring:        getJWTKeyringConfiguration(),
+		FakeUserPassword:  viper.GetString("FAKE_USER_PASSWORD"),
+		SessionStore:      viper.GetString("SESSION_STORE"),
+		LoginAttemptStore: viper.GetString("LOGIN_ATTEMPT_STORE"),
+		NotificationFile:  viper.GetString("NOTIFICATION_FILE"),
+		PasswordHasher: &password_hasher.Configuration{
+			Algorithm:         viper.GetString("PASSWORD_HASH_ALGORITHM"),
+			BcryptCost:        viper.GetInt("BCRYPT_COST"),
+			Argon2Memory:      viper.GetUint32("ARGON2_MEMORY"),
+			Argon2Iterations:  viper.GetUint32("ARGON2_ITERATIONS"),
+			Argon2Parallelism: uint8(viper.GetUint("ARGON2_PARALLELISM")),
+		},
+		UserRetention: viper.GetDuration("USER_RETENTION"),
 		DBClusterConfig: &db.ClusterConfiguration{
-			Master: getDatabaseConfiguration("MASTER"),
-			Sync:   getDatabaseConfiguration("SYNC"),
-			Async:  getDatabaseConfiguration("ASYNC"),
+			Nodes:             getNodeConfigurations(),
+			RoutingStrategy:   db.RoutingStrategy(viper.GetString("DB_ROUTING_STRATEGY")),
+			MaxReplicationLag: viper.GetDuration("DB_MAX_REPLICATION_LAG"),
 		},
 	}
 }
 
+// getJWTKeyringConfiguration reads JWT keys from environment variables:
+// JWT_SECRET_KEY is an HS256 secret with the ID from JWT_SECRET_KEY_ID,
+// JWT_KEYS is a comma-separated list of "id:algorithm:value" keys,
+// where the value is a secret for HS256 and a path to a PEM file for RS256 and EdDSA,
+// JWT_SIGNING_KEY_ID is the ID of the key used to sign new tokens.
+func getJWTKeyringConfiguration() *keyring.Configuration {
+	c := &keyring.Configuration{
+		SigningKeyID: viper.GetString("JWT_SIGNING_KEY_ID"),
+	}
+
+	if secret := viper.GetString("JWT_SECRET_KEY"); secret != "" {
+		id := viper.GetString("JWT_SECRET_KEY_ID")
+		if id == "" {
+			id = defaultJWTSecretKeyID
+		}
+
+		c.Keys = append(c.Keys, &keyring.KeyConfiguration{
+			ID:        id,
+			Algorithm: keyring.AlgorithmHS256,
+			Secret:    []byte(secret),
+		})
+	}
+
+	keys := viper.GetString("JWT_KEYS")
+	if keys == "" {
+		return c
+	}
+
+	for _, v := range strings.Split(keys, jwtKeysSeparator) {
+		fields := strings.SplitN(strings.TrimSpace(v), jwtKeyFieldsSeparator, jwtKeyFieldsCount)
+		if len(fields) != jwtKeyFieldsCount {
+			// Malformed keys are kept to be reported by validation.
+			c.Keys = append(c.Keys, &keyring.KeyConfiguration{ID: v})
+			continue
+		}
+
+		key := &keyring.KeyConfiguration{
+			ID:        fields[0],
+			Algorithm: fields[1],
+		}
+
+		if key.Algorithm == keyring.AlgorithmHS256 {
+			key.Secret = []byte(fields[2])
+		} else {
+			key.PEMFile = fields[2]
+		}
+
+		c.Keys = append(c.Keys, key)
+	}
+
+	return c
+}
+
+// getNodeConfigurations reads the databases of the cluster from environment variables:
+// DB_NODES is a comma-separated list of database names ("master,sync,async" by default),
+// the settings of each database are read from variables prefixed with DB_<NAME>,
+// e.g. DB_MASTER_HOST, DB_SYNC_ROLE and DB_ASYNC_WEIGHT.
+func getNodeConfigurations() []*db.NodeConfiguration {
+	names := viper.GetString("DB_NODES")
+	if names == "" {
+		names = defaultDBNodes
+	}
+
+	var result []*db.NodeConfiguration
+
+	for _, name := range strings.Split(names, dbNodesSeparator) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		prefix := strings.ToUpper(name)
+
+		result = append(result, &db.NodeConfiguration{
+			Name:       name,
+			Role:       db.NodeRole(viper.GetString(strings.Join([]string{"DB", prefix, "ROLE"}, "_"))),
+			Weight:     viper.GetUint32(strings.Join([]string{"DB", prefix, "WEIGHT"}, "_")),
+			Connection: getDatabaseConfiguration(prefix),
+		})
+	}
+
+	return result
+}
+
 func getDatabaseConfiguration(prefix string) *db.DatabaseConfiguration {
 	addPrefix := func(key string) string {
 		return strings.Join([]string{"DB", prefix, key}, "_")
@@ -90,7 +219,7 @@ func (c *Configuration) Validate() error {
 		return errConfigIsEmpty
 	}
 
-	if len(c.JWTSecretKey) == 0 {
+	if c.JWTKeyring == nil || len(c.JWTKeyring.Keys) == 0 {
 		return errJWTKeyIsEmpty
 	}
 
@@ -98,16 +227,15 @@ func (c *Configuration) Validate() error {
 		return errFakeUserPasswordIsEmpty
 	}
 
-	dbc := c.DBClusterConfig
-	if err := dbc.Master.Validate("master"); err != nil {
-		return err
+	if !isKnownStore(c.SessionStore) {
+		return fmt.Errorf("%w: %s", errUnknownSessionStore, c.SessionStore)
 	}
 
-	if err := dbc.Sync.Validate("sync"); err != nil {
-		return err
+	if !isKnownStore(c.LoginAttemptStore) {
+		return fmt.Errorf("%w: %s", errUnknownLoginAttemptStore, c.LoginAttemptStore)
 	}
 
-	return dbc.Async.Validate("async")
+	return c.DBClusterConfig.Validate()
 }
 
 func (c *Configuration) enrichEmptyFieldsWithDefaults() {
@@ -123,18 +251,77 @@ func (c *Configuration) enrichEmptyFieldsWithDefaults() {
 		c.RequestTimeout = defaultRequestTimeout
 	}
 
+	if c.SessionStore == "" {
+		c.SessionStore = defaultSessionStore
+	}
+
+	if c.LoginAttemptStore == "" {
+		c.LoginAttemptStore = defaultLoginAttemptStore
+	}
+
+	if c.UserRetention == 0 {
+		c.UserRetention = defaultUserRetention
+	}
+
+	if ph := c.PasswordHasher; ph != nil {
+		c.enrichEmptyPasswordHasherConfig(ph)
+	}
+
 	if dbc := c.DBClusterConfig; dbc != nil {
-		c.enrichEmptyDBConfig(dbc.Master)
-		c.enrichEmptyDBConfig(dbc.Sync)
-		c.enrichEmptyDBConfig(dbc.Async)
+		if dbc.MaxReplicationLag == 0 {
+			dbc.MaxReplicationLag = defaultDBMaxReplicationLag
+		}
+
+		if dbc.RoutingStrategy == "" {
+			dbc.RoutingStrategy = defaultDBRoutingStrategy
+		}
+
+		for _, node := range dbc.Nodes {
+			c.enrichEmptyNodeConfig(node)
+		}
 	}
 }
 
-func (c *Configuration) enrichEmptyDBConfig(v *db.DatabaseConfiguration) {
-	if v == nil {
-		v = &db.DatabaseConfiguration{}
+func (c *Configuration) enrichEmptyPasswordHasherConfig(v *password_hasher.Configuration) {
+	if v.Algorithm == "" {
+		v.Algorithm = defaultPasswordHashAlgorithm
+	}
+
+	if v.BcryptCost == 0 {
+		v.BcryptCost = defaultBcryptCost
+	}
+
+	if v.Argon2Memory == 0 {
+		v.Argon2Memory = defaultArgon2Memory
+	}
+
+	if v.Argon2Iterations == 0 {
+		v.Argon2Iterations = defaultArgon2Iterations
+	}
+
+	if v.Argon2Parallelism == 0 {
+		v.Argon2Parallelism = defaultArgon2Parallelism
+	}
+}
+
+func (c *Configuration) enrichEmptyNodeConfig(v *db.NodeConfiguration) {
+	if v.Role == "" {
+		v.Role = db.RoleReplica
+		if v.Name == defaultDBMasterName {
+			v.Role = db.RoleMaster
+		}
+	}
+
+	if v.Weight == 0 {
+		v.Weight = defaultDBWeight
+	}
+
+	if v.Connection != nil {
+		c.enrichEmptyDBConfig(v.Connection)
 	}
+}
 
+func (c *Configuration) enrichEmptyDBConfig(v *db.DatabaseConfiguration) {
 	if v.MaxConnections == 0 {
 		v.MaxConnections = defaultDBMaxConnections
 	}
@@ -143,3 +330,7 @@ func (c *Configuration) enrichEmptyDBConfig(v *db.DatabaseConfiguration) {
 		v.ConnectionLifetime = defaultDBConnectionLifetime
 	}
 }
+
+func isKnownStore(v string) bool {
+	return v == StoreMemory || v == StorePostgres
+}
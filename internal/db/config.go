@@ -1,10 +1,14 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 type (
@@ -26,28 +30,142 @@ type (
 		ConnectionLifetime time.Duration `mapstructure:"connectionLifetime"`
 	}
 
+	// NodeRole is the role of a database in the cluster.
+	NodeRole string
+
+	// RoutingStrategy is the way reads are distributed between the databases of the cluster.
+	RoutingStrategy string
+
+	// NodeConfiguration represents the configuration of a database of the cluster.
+	NodeConfiguration struct {
+		// Name of the database in the cluster, used in logs and metrics.
+		Name string
+		// Role of the database: the master, which all other databases replicate from, or a replica.
+		Role NodeRole
+		// Share of reads the database gets with the weighted routing strategy relative to other databases.
+		Weight uint32
+		// Configuration needed to connect to the database.
+		Connection *DatabaseConfiguration
+	}
+
 	// ClusterConfiguration represents the configuration needed to set up a PostgreSQL cluster
-	// with synchronous and asynchronous replicas.
+	// of a master and any number of replicas.
 	ClusterConfiguration struct {
-		// Configuration for the master PostgreSQL server, which all other servers will replicate from.
-		Master *DatabaseConfiguration
-		// Configuration for the synchronous replica PostgreSQL server.
-		Sync *DatabaseConfiguration
-		// Configuration for the asynchronous replica PostgreSQL server.
-		Async *DatabaseConfiguration
+		// Configuration of the databases of the cluster, exactly one of them must be the master.
+		Nodes []*NodeConfiguration
+		// Way reads are distributed between the replicas.
+		RoutingStrategy RoutingStrategy
+		// Maximum replication lag of a replica to be used for reads.
+		MaxReplicationLag time.Duration
 	}
 
-	// Cluster represents a PostgreSQL cluster consisting of a master database and synchronous and asynchronous replicas.
+	// Cluster represents a PostgreSQL cluster consisting of a master database and its replicas.
+	// The pools are only handed out as ReaderDB and WriterDB, so that a write can't be sent to a replica.
 	Cluster struct {
 		// Connection pool for the master database.
-		Master *pgxpool.Pool
-		// Connection pool for the synchronous replica database.
-		Sync *pgxpool.Pool
-		// Connection pool for the asynchronous replica database.
-		Async *pgxpool.Pool
+		master *pgxpool.Pool
+		// Read-only connection pool for the master database, used for reads when no replica is available.
+		masterReader *readerDB
+		// Way reads are distributed between the replicas.
+		strategy RoutingStrategy
+
+		// Health of the master and the replicas.
+		health []*poolHealth
+		// Health of the replicas in the configured order.
+		replicas []*poolHealth
+		// Gauge of the health of all databases.
+		healthGauge *prometheus.GaugeVec
+		// Gauge of the replication lag of the replicas.
+		lagGauge *prometheus.GaugeVec
+		// Counter used to choose the next database for round robin and weighted routing.
+		roundRobinIndex uint32
+		// Stops the health checker.
+		cancel context.CancelFunc
+		mu     sync.Mutex
 	}
 )
 
+// Roles of the databases in the cluster.
+const (
+	RoleMaster  NodeRole = "master"  // The database all writes go to.
+	RoleReplica NodeRole = "replica" // A read-only replica of the master.
+)
+
+// Strategies of routing reads between the databases.
+const (
+	// RoutingRoundRobin sends reads to the databases in turn.
+	RoutingRoundRobin RoutingStrategy = "round_robin"
+	// RoutingWeighted sends reads to the databases in turn in proportion to their weights.
+	RoutingWeighted RoutingStrategy = "weighted"
+	// RoutingLeastAcquired sends reads to the database with the least acquired connections.
+	RoutingLeastAcquired RoutingStrategy = "least_acquired"
+)
+
+// Errors that can occur during cluster configuration validation.
+var (
+	errClusterConfigIsEmpty   = errors.New("database cluster configuration is empty")
+	errUnknownRoutingStrategy = errors.New("unknown database routing strategy")
+	errUnknownNodeRole        = errors.New("unknown database role")
+	errNodeNameIsEmpty        = errors.New("database name is empty")
+	errDuplicateNodeName      = errors.New("duplicate database name")
+	errMasterCount            = errors.New("database cluster must have exactly one master")
+	errMaxReplicationLag      = errors.New("maximum replication lag must be greater than 0")
+)
+
+// Validate checks cluster configuration for errors.
+func (v *ClusterConfiguration) Validate() error {
+	if v == nil {
+		return errClusterConfigIsEmpty
+	}
+
+	if v.MaxReplicationLag <= 0 {
+		return errMaxReplicationLag
+	}
+
+	if !v.RoutingStrategy.isKnown() {
+		return fmt.Errorf("%w: %s", errUnknownRoutingStrategy, v.RoutingStrategy)
+	}
+
+	var (
+		names       = make(map[string]struct{}, len(v.Nodes))
+		masterCount int
+	)
+
+	for _, node := range v.Nodes {
+		if node == nil || node.Name == "" {
+			return errNodeNameIsEmpty
+		}
+
+		if _, ok := names[node.Name]; ok {
+			return fmt.Errorf("%w: %s", errDuplicateNodeName, node.Name)
+		}
+
+		names[node.Name] = struct{}{}
+
+		switch node.Role {
+		case RoleMaster:
+			masterCount++
+		case RoleReplica:
+		default:
+			return fmt.Errorf("%w: %s", errUnknownNodeRole, node.Role)
+		}
+
+		if err := node.Connection.Validate(node.Name); err != nil {
+			return err
+		}
+	}
+
+	if masterCount != 1 {
+		return errMasterCount
+	}
+
+	return nil
+}
+
+func (s RoutingStrategy) isKnown() bool {
+	return s == RoutingRoundRobin || s == RoutingWeighted || s == RoutingLeastAcquired
+}
+
 // Validate checks database configuration for errors.
 func (v *DatabaseConfiguration) Validate(name string) error {
 	if v == nil {
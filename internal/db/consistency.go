@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/oshokin/hive-backend/internal/common"
+	"github.com/oshokin/hive-backend/internal/logger"
+)
+
+type (
+	// LSN is a position in the write-ahead log of PostgreSQL.
+	LSN uint64
+
+	// Consistency carries the position in the write-ahead log that reads must see,
+	// so that a client reads its own writes even from replicas.
+	Consistency struct {
+		minLSN atomic.Uint64
+		// OnWrite is called with the position of the master after every tracked write.
+		OnWrite func(lsn LSN)
+	}
+
+	consistencyKey struct{}
+)
+
+// currentLSNQuery returns the current position of the master in the write-ahead log as a number.
+const currentLSNQuery = "SELECT pg_wal_lsn_diff(pg_current_wal_lsn(), '0/0')::bigint"
+
+// ParseLSN parses the textual representation of LSN, e.g. "16/B374D848".
+func ParseLSN(s string) (LSN, error) {
+	var hi, lo uint32
+	if _, err := fmt.Sscanf(s, "%X/%X", &hi, &lo); err != nil {
+		return 0, fmt.Errorf("invalid LSN %q: %w", s, err)
+	}
+
+	return LSN(uint64(hi)<<32 | uint64(lo)), nil
+}
+
+// String returns the textual representation of LSN used by PostgreSQL.
+func (l LSN) String() string {
+	return fmt.Sprintf("%X/%X", uint32(l>>32), uint32(l))
+}
+
+// NewConsistency returns a new Consistency, reads with which must see the given position.
+func NewConsistency(minLSN LSN, onWrite func(lsn LSN)) *Consistency {
+	c := &Consistency{
+		OnWrite: onWrite,
+	}
+
+	c.minLSN.Store(uint64(minLSN))
+
+	return c
+}
+
+// WithConsistency returns a copy of the context that carries the consistency requirement.
+func WithConsistency(ctx context.Context, c *Consistency) context.Context {
+	return context.WithValue(ctx, consistencyKey{}, c)
+}
+
+func getConsistency(ctx context.Context) *Consistency {
+	c, _ := ctx.Value(consistencyKey{}).(*Consistency)
+	return c
+}
+
+// getMinLSN returns the position reads with the context must see, 0 if any replica will do.
+func getMinLSN(ctx context.Context) LSN {
+	if c := getConsistency(ctx); c != nil {
+		return LSN(c.minLSN.Load())
+	}
+
+	return 0
+}
+
+// raiseMinLSN makes reads see at least the given position.
+func (c *Consistency) raiseMinLSN(lsn LSN) {
+	for {
+		current := c.minLSN.Load()
+		if uint64(lsn) <= current || c.minLSN.CompareAndSwap(current, uint64(lsn)) {
+			return
+		}
+	}
+}
+
+// TrackWrite reads the position of the master after a write made with the context,
+// so that further reads with the context and with the token returned to the client see the write.
+// It does nothing if the context carries no consistency requirement.
+// The write has already succeeded, so errors are only logged.
+func (c *Cluster) TrackWrite(ctx context.Context) {
+	consistency := getConsistency(ctx)
+	if consistency == nil {
+		return
+	}
+
+	var lsn int64
+	if err := c.master.QueryRow(ctx, currentLSNQuery).Scan(&lsn); err != nil {
+		logger.ErrorKV(ctx, "failed to read current WAL position", common.ErrorTag, err)
+		return
+	}
+
+	consistency.raiseMinLSN(LSN(lsn))
+
+	if consistency.OnWrite != nil {
+		consistency.OnWrite(LSN(consistency.minLSN.Load()))
+	}
+}
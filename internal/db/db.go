@@ -3,7 +3,6 @@ package db
 import (
 	"context"
 	"fmt"
-	"sync/atomic"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/oshokin/hive-backend/internal/common"
@@ -12,71 +11,93 @@ import (
 )
 
 const (
-	dbCount         = 3
-	readOnlyDBCount = 2
+	// readOnlyParam makes all transactions of a session read-only.
+	readOnlyParam = "default_transaction_read_only"
+	// masterReaderSuffix is added to the name of the master in metrics of its read-only pool.
+	masterReaderSuffix = "_reader"
 )
 
-var roundRobinIndex uint32
-
 // NewCluster takes a context and a ClusterConfiguration and returns a Cluster,
-// which consists of three connection pools:
-// a master pool, a synchronous replica pool, and an asynchronous replica pool.
-// It creates the three pools concurrently and returns an error if any of the pools failed to be created.
+// which consists of a connection pool for every configured database
+// and a read-only pool for the master used when no replica is available for reads.
+// It creates the pools concurrently and returns an error if any of the pools failed to be created.
 func NewCluster(ctx context.Context, v *ClusterConfiguration) (*Cluster, error) {
 	var (
-		master *pgxpool.Pool
-		sync   *pgxpool.Pool
-		async  *pgxpool.Pool
-		wg     = common.GetDefaultWG(dbCount)
+		pools            = make([]*pgxpool.Pool, len(v.Nodes))
+		masterReaderPool *pgxpool.Pool
+		wg               = common.GetDefaultWG(len(v.Nodes) + 1)
 	)
 
-	wg.Add(
-		func() (localErr error) {
-			master, localErr = newPool(ctx, v.Master)
-			if localErr != nil {
-				return fmt.Errorf("failed to create master database pool: %w", localErr)
-			}
-
-			return nil
-		},
-		func() (localErr error) {
-			sync, localErr = newPool(ctx, v.Sync)
-			if localErr != nil {
-				return fmt.Errorf("failed to create sync database pool: %w", localErr)
-			}
+	for i, node := range v.Nodes {
+		i, node := i, node
 
-			return nil
-		},
-		func() (localErr error) {
-			async, localErr = newPool(ctx, v.Async)
+		wg.Add(func() (localErr error) {
+			pools[i], localErr = newPool(ctx, node.Connection, node.Role == RoleReplica)
 			if localErr != nil {
-				return fmt.Errorf("failed to create async database pool: %w", localErr)
+				return fmt.Errorf("failed to create %s database pool: %w", node.Name, localErr)
 			}
 
 			return nil
 		})
 
+		if node.Role == RoleMaster {
+			wg.Add(func() (localErr error) {
+				masterReaderPool, localErr = newPool(ctx, node.Connection, true)
+				if localErr != nil {
+					return fmt.Errorf("failed to create %s database read-only pool: %w", node.Name, localErr)
+				}
+
+				return nil
+			})
+		}
+	}
+
 	if err := wg.Start().GetLastError(); err != nil {
+		closePools(append(pools, masterReaderPool))
 		return nil, err
 	}
 
-	staters := map[string]pgx_pool_collector.Stater{
-		"master": master,
-		"sync":   sync,
-		"async":  async,
+	var (
+		c = &Cluster{
+			masterReader: &readerDB{pool: masterReaderPool},
+			strategy:     v.RoutingStrategy,
+			health:       make([]*poolHealth, 0, len(v.Nodes)),
+			healthGauge:  newHealthGauge(),
+			lagGauge:     newLagGauge(),
+		}
+		staters = make(map[string]pgx_pool_collector.Stater, len(v.Nodes)+1)
+	)
+
+	for i, node := range v.Nodes {
+		var (
+			pool   = pools[i]
+			reader = &readerDB{pool: pool}
+		)
+
+		if node.Role == RoleMaster {
+			c.master = pool
+			reader = c.masterReader
+			staters[node.Name+masterReaderSuffix] = masterReaderPool
+		}
+
+		h := newPoolHealth(node, pool, reader, v.MaxReplicationLag)
+
+		c.health = append(c.health, h)
+		if h.isReplica() {
+			c.replicas = append(c.replicas, h)
+		}
+
+		staters[node.Name] = pool
 	}
 
-	collector := pgx_pool_collector.NewCollector(staters, nil)
-	prometheus.MustRegister(collector)
+	prometheus.MustRegister(pgx_pool_collector.NewCollector(staters, nil), c.healthGauge, c.lagGauge)
 
-	return &Cluster{
-		Master: master,
-		Sync:   sync,
-		Async:  async,
-	}, nil
+	return c, nil
 }
 
-func newPool(ctx context.Context, v *DatabaseConfiguration) (*pgxpool.Pool, error) {
+// newPool creates a connection pool to the database,
+// sessions of a read-only pool reject queries that change data.
+func newPool(ctx context.Context, v *DatabaseConfiguration, isReadOnly bool) (*pgxpool.Pool, error) {
 	poolConfig, err := pgxpool.ParseConfig("")
 	if err != nil {
 		return nil, err
@@ -89,6 +110,10 @@ func newPool(ctx context.Context, v *DatabaseConfiguration) (*pgxpool.Pool, erro
 	connConfig.User = v.User
 	connConfig.Password = v.Password
 
+	if isReadOnly {
+		connConfig.RuntimeParams[readOnlyParam] = "on"
+	}
+
 	poolConfig.MaxConnLifetime = v.ConnectionLifetime
 	poolConfig.MaxConns = int32(v.MaxConnections)
 
@@ -101,60 +126,44 @@ func newPool(ctx context.Context, v *DatabaseConfiguration) (*pgxpool.Pool, erro
 }
 
 // Write returns the connection pool for the master database.
-func (c *Cluster) Write() *pgxpool.Pool {
-	return c.Master
-}
-
-// ReadSync returns the connection pool for the synchronous replica database.
-func (c *Cluster) ReadSync() *pgxpool.Pool {
-	return c.Sync
+func (c *Cluster) Write() WriterDB {
+	return c.master
 }
 
-// ReadAsync returns the connection pool for the asynchronous replica database.
-func (c *Cluster) ReadAsync() *pgxpool.Pool {
-	return c.Async
+// RR returns a connection pool chosen by the routing strategy among all databases.
+// Unhealthy databases and replicas that haven't replayed the writes the context must see are skipped,
+// the master is returned if none are available.
+func (c *Cluster) RR(ctx context.Context) ReaderDB {
+	return c.nextAvailable(c.health, getMinLSN(ctx))
 }
 
-// RR returns a connection pool based on a round robin algorithm.
-func (c *Cluster) RR() *pgxpool.Pool {
-	idx := atomic.AddUint32(&roundRobinIndex, 1) % dbCount
-	switch idx {
-	case 1:
-		return c.Master
-	case 2:
-		return c.Sync
-	default:
-		return c.Async
-	}
-}
-
-// ReadRR returns a read only connection pool based on a round robin algorithm.
-func (c *Cluster) ReadRR() *pgxpool.Pool {
-	idx := atomic.AddUint32(&roundRobinIndex, 1) % readOnlyDBCount
-	if idx == 0 {
-		return c.Sync
-	}
-
-	return c.Async
+// ReadRR returns a read-only connection pool chosen by the routing strategy among the replicas.
+// Unhealthy and lagging replicas and replicas that haven't replayed the writes the context must see are skipped,
+// the master is returned if none of them are available.
+func (c *Cluster) ReadRR(ctx context.Context) ReaderDB {
+	return c.nextAvailable(c.replicas, getMinLSN(ctx))
 }
 
 // Close closes the connections to all databases in the cluster.
-// If any of the connections are nil, they will not be closed.
 // This method should always be called when the cluster is no longer needed.
 func (c *Cluster) Close() {
 	if c == nil {
 		return
 	}
 
-	if c.Master != nil {
-		c.Master.Close()
+	for _, h := range c.health {
+		h.pool.Close()
 	}
 
-	if c.Sync != nil {
-		c.Sync.Close()
+	if c.masterReader != nil {
+		c.masterReader.pool.Close()
 	}
+}
 
-	if c.Async != nil {
-		c.Async.Close()
+func closePools(pools []*pgxpool.Pool) {
+	for _, pool := range pools {
+		if pool != nil {
+			pool.Close()
+		}
 	}
 }
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type (
+	// ReaderDB is a handle for queries that only read data.
+	// Its connections are read-only sessions, so a query that changes data fails
+	// even if the reads are sent to the master.
+	ReaderDB interface {
+		Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+		QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	}
+
+	// WriterDB is a handle for queries that change data, it always points to the master.
+	// It's implemented by both a connection pool and a transaction.
+	WriterDB interface {
+		ReaderDB
+		Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+		CopyFrom(ctx context.Context,
+			tableName pgx.Identifier,
+			columnNames []string,
+			rowSrc pgx.CopyFromSource) (int64, error)
+		Begin(ctx context.Context) (pgx.Tx, error)
+	}
+
+	// readerDB is the implementation of ReaderDB handed out by the cluster.
+	// It hides the pool, so that a reader can't be type asserted to WriterDB.
+	readerDB struct {
+		pool *pgxpool.Pool
+	}
+)
+
+func (r *readerDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return r.pool.Query(ctx, sql, args...)
+}
+
+func (r *readerDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return r.pool.QueryRow(ctx, sql, args...)
+}
@@ -0,0 +1,252 @@
+package db
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/oshokin/hive-backend/internal/common"
+	"github.com/oshokin/hive-backend/internal/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolHealth tracks the health of a connection pool with hysteresis:
+// the pool is marked down after several failed pings in a row and up after several successful ones,
+// so that a single lost ping doesn't make the reads flap between databases.
+// A replica is also marked lagging when its replication lag exceeds the maximum
+// and stops lagging when the lag falls below half of the maximum.
+type poolHealth struct {
+	name      string
+	pool      *pgxpool.Pool
+	reader    *readerDB // Read-only pool of the database, a separate one for the master.
+	role      NodeRole
+	weight    uint32
+	maxLag    time.Duration // Maximum replication lag, unused for the master.
+	isHealthy atomic.Bool
+	isLagging atomic.Bool
+	replayLSN atomic.Uint64 // Position of the replica in the write-ahead log.
+	// Only the health checker goroutine accesses the fields below.
+	successCount int
+	failureCount int
+	lag          time.Duration
+}
+
+const (
+	healthCheckInterval  = 5 * time.Second
+	healthCheckTimeout   = 1 * time.Second
+	healthyAfterSuccess  = 2
+	unhealthyAfterFailed = 3
+	poolNameLabel        = "db"
+	// replicationLagQuery returns the lag of the replica in seconds and its replayed position in the write-ahead log.
+	// The replica doesn't lag if it has replayed everything it has received,
+	// otherwise the lag is the time since the last replayed transaction.
+	replicationLagQuery = `SELECT
+	CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE coalesce(extract(epoch FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END::float8,
+	coalesce(pg_wal_lsn_diff(pg_last_wal_replay_lsn(), '0/0'), 0)::bigint`
+)
+
+func newPoolHealth(node *NodeConfiguration,
+	pool *pgxpool.Pool,
+	reader *readerDB,
+	maxLag time.Duration) *poolHealth {
+	h := &poolHealth{
+		name:   node.Name,
+		pool:   pool,
+		reader: reader,
+		role:   node.Role,
+		weight: node.Weight,
+		maxLag: maxLag,
+	}
+
+	// The pool is considered healthy until it fails enough pings.
+	h.isHealthy.Store(true)
+
+	return h
+}
+
+func newHealthGauge() *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pgxpool_healthy",
+		Help: "Whether the database is healthy (1) or not (0) according to the health checker.",
+	}, []string{poolNameLabel})
+}
+
+func newLagGauge() *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pgxpool_replication_lag_seconds",
+		Help: "Replication lag of the replica database in seconds according to the health checker.",
+	}, []string{poolNameLabel})
+}
+
+// isAvailable reports whether the pool can be used for reads that must see the given position,
+// 0 means any position will do.
+func (h *poolHealth) isAvailable(minLSN LSN) bool {
+	if !h.isHealthy.Load() || h.isLagging.Load() {
+		return false
+	}
+
+	// The master always has the latest position.
+	return minLSN == 0 || !h.isReplica() || LSN(h.replayLSN.Load()) >= minLSN
+}
+
+func (h *poolHealth) isReplica() bool {
+	return h.role == RoleReplica
+}
+
+// check pings the database (replicas are asked for their lag instead) and updates its state,
+// returns true if the state has changed.
+func (h *poolHealth) check(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	var err error
+	if h.isReplica() {
+		err = h.checkLag(ctx)
+	} else {
+		err = h.pool.Ping(ctx)
+	}
+
+	if err != nil {
+		h.successCount = 0
+		h.failureCount++
+
+		if h.failureCount >= unhealthyAfterFailed && h.isHealthy.Load() {
+			h.isHealthy.Store(false)
+			logger.WarnKV(ctx, "database is down",
+				common.DatabaseTag, h.name,
+				common.ErrorTag, err)
+
+			return true
+		}
+
+		return false
+	}
+
+	h.failureCount = 0
+	h.successCount++
+
+	isChanged := h.updateLagging(ctx)
+
+	if h.successCount >= healthyAfterSuccess && !h.isHealthy.Load() {
+		h.isHealthy.Store(true)
+		logger.InfoKV(ctx, "database is up", common.DatabaseTag, h.name)
+
+		return true
+	}
+
+	return isChanged
+}
+
+func (h *poolHealth) checkLag(ctx context.Context) error {
+	var (
+		lagSeconds float64
+		replayLSN  int64
+	)
+
+	if err := h.pool.QueryRow(ctx, replicationLagQuery).Scan(&lagSeconds, &replayLSN); err != nil {
+		return err
+	}
+
+	h.lag = time.Duration(lagSeconds * float64(time.Second))
+	h.replayLSN.Store(uint64(replayLSN))
+
+	return nil
+}
+
+// updateLagging marks the replica lagging or not depending on its last lag,
+// returns true if the state has changed.
+func (h *poolHealth) updateLagging(ctx context.Context) bool {
+	if !h.isReplica() {
+		return false
+	}
+
+	switch {
+	case h.lag > h.maxLag && !h.isLagging.Load():
+		h.isLagging.Store(true)
+		logger.WarnKV(ctx, "database replication lags behind",
+			common.DatabaseTag, h.name,
+			common.ReplicationLagTag, h.lag)
+	case h.lag <= h.maxLag/2 && h.isLagging.Load():
+		h.isLagging.Store(false)
+		logger.InfoKV(ctx, "database replication caught up",
+			common.DatabaseTag, h.name,
+			common.ReplicationLagTag, h.lag)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// Start starts the background health checker,
+// which pings all databases and excludes unhealthy replicas from reads.
+func (c *Cluster) Start(ctx context.Context) {
+	logger.Info(ctx, "starting database health checker")
+
+	c.mu.Lock()
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.mu.Unlock()
+
+	c.updateHealthGauge()
+
+	go func() {
+		ticker := time.NewTicker(healthCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.checkHealth(ctx)
+			}
+		}
+	}()
+
+	logger.Info(ctx, "database health checker is running")
+}
+
+// Stop stops the background health checker.
+func (c *Cluster) Stop(ctx context.Context) {
+	logger.Info(ctx, "shutting down database health checker")
+	c.mu.Lock()
+
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	c.mu.Unlock()
+	logger.Info(ctx, "database health checker stopped")
+}
+
+func (c *Cluster) checkHealth(ctx context.Context) {
+	isChanged := false
+
+	for _, h := range c.health {
+		if h.check(ctx) {
+			isChanged = true
+		}
+	}
+
+	if isChanged {
+		c.updateHealthGauge()
+	}
+
+	for _, h := range c.replicas {
+		c.lagGauge.WithLabelValues(h.name).Set(h.lag.Seconds())
+	}
+}
+
+func (c *Cluster) updateHealthGauge() {
+	for _, h := range c.health {
+		value := float64(0)
+		if h.isHealthy.Load() {
+			value = 1
+		}
+
+		c.healthGauge.WithLabelValues(h.name).Set(value)
+	}
+}
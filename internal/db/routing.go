@@ -0,0 +1,101 @@
+package db
+
+import "sync/atomic"
+
+// nextAvailable chooses an available database among the candidates with the routing strategy of the cluster,
+// the master is returned if none are available.
+func (c *Cluster) nextAvailable(candidates []*poolHealth, minLSN LSN) ReaderDB {
+	var h *poolHealth
+
+	switch c.strategy {
+	case RoutingWeighted:
+		h = c.nextWeighted(candidates, minLSN)
+	case RoutingLeastAcquired:
+		h = c.leastAcquired(candidates, minLSN)
+	default:
+		h = c.nextRoundRobin(candidates, minLSN)
+	}
+
+	if h == nil {
+		return c.masterReader
+	}
+
+	return h.reader
+}
+
+// nextRoundRobin returns the next available candidate in turn.
+func (c *Cluster) nextRoundRobin(candidates []*poolHealth, minLSN LSN) *poolHealth {
+	count := uint32(len(candidates))
+	if count == 0 {
+		return nil
+	}
+
+	return firstAvailable(candidates, atomic.AddUint32(&c.roundRobinIndex, 1)%count, minLSN)
+}
+
+// nextWeighted returns the next available candidate in turn,
+// each candidate gets as many turns in a row as its weight.
+// If the chosen candidate isn't available, the read goes to the next available one.
+func (c *Cluster) nextWeighted(candidates []*poolHealth, minLSN LSN) *poolHealth {
+	var totalWeight uint32
+	for _, h := range candidates {
+		totalWeight += h.weight
+	}
+
+	if totalWeight == 0 {
+		return c.nextRoundRobin(candidates, minLSN)
+	}
+
+	turn := atomic.AddUint32(&c.roundRobinIndex, 1) % totalWeight
+	for i, h := range candidates {
+		if turn < h.weight {
+			return firstAvailable(candidates, uint32(i), minLSN)
+		}
+
+		turn -= h.weight
+	}
+
+	return nil
+}
+
+// leastAcquired returns the available candidate with the least acquired connections,
+// ties are broken in turn, so that idle databases share the reads.
+func (c *Cluster) leastAcquired(candidates []*poolHealth, minLSN LSN) *poolHealth {
+	count := uint32(len(candidates))
+	if count == 0 {
+		return nil
+	}
+
+	var (
+		start       = atomic.AddUint32(&c.roundRobinIndex, 1)
+		result      *poolHealth
+		minAcquired int32
+	)
+
+	for i := uint32(0); i < count; i++ {
+		h := candidates[(start+i)%count]
+		if !h.isAvailable(minLSN) {
+			continue
+		}
+
+		acquired := h.reader.pool.Stat().AcquiredConns()
+		if result == nil || acquired < minAcquired {
+			result, minAcquired = h, acquired
+		}
+	}
+
+	return result
+}
+
+// firstAvailable returns the first available candidate starting from the given index.
+func firstAvailable(candidates []*poolHealth, start uint32, minLSN LSN) *poolHealth {
+	count := uint32(len(candidates))
+
+	for i := uint32(0); i < count; i++ {
+		if h := candidates[(start+i)%count]; h.isAvailable(minLSN) {
+			return h
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+type (
+	fileNotifier struct {
+		path string
+		mu   sync.Mutex
+	}
+
+	fileRecord struct {
+		SentAt  time.Time `json:"sent_at"`
+		UserID  int64     `json:"user_id"`
+		Email   string    `json:"email"`
+		Subject string    `json:"subject"`
+		Text    string    `json:"text"`
+	}
+)
+
+// NewFileNotifier creates a new Notifier which appends messages to the file as JSON lines.
+// It's meant for local development and tests, where a real mail server isn't available.
+func NewFileNotifier(path string) Notifier {
+	return &fileNotifier{
+		path: path,
+	}
+}
+
+func (n *fileNotifier) Notify(_ context.Context, m *Message) error {
+	line, err := json.Marshal(&fileRecord{
+		SentAt:  time.Now(),
+		UserID:  m.UserID,
+		Email:   m.Email,
+		Subject: m.Subject,
+		Text:    m.Text,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+
+	return nil
+}
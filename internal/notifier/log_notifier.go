@@ -0,0 +1,25 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/oshokin/hive-backend/internal/common"
+	"github.com/oshokin/hive-backend/internal/logger"
+)
+
+type logNotifier struct{}
+
+// NewLogNotifier creates a new Notifier which writes messages to the application log.
+// It's meant for local development only, since messages may contain secrets.
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Notify(ctx context.Context, m *Message) error {
+	logger.InfoKV(ctx, m.Subject,
+		common.UserIDTag, m.UserID,
+		common.EmailTag, m.Email,
+		common.MessageTag, m.Text)
+
+	return nil
+}
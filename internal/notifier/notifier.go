@@ -0,0 +1,20 @@
+// Package notifier provides an interface and implementations of delivering notifications to users.
+package notifier
+
+import "context"
+
+type (
+	// Notifier delivers notifications to users.
+	Notifier interface {
+		// Notify delivers the message to its recipient.
+		Notify(ctx context.Context, m *Message) error
+	}
+
+	// Message represents a notification for a user.
+	Message struct {
+		UserID  int64  // ID of the recipient.
+		Email   string // E-mail address of the recipient.
+		Subject string // Subject of the message.
+		Text    string // Text of the message.
+	}
+)
@@ -58,7 +58,7 @@ func (r *repository) CheckIfExistByIDs(ctx context.Context, cityIDs []int16) (ma
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	rows, err := r.cluster.ReadRR().Query(ctx, selectQuery, selectArgs...)
+	rows, err := r.cluster.ReadRR(ctx).Query(ctx, selectQuery, selectArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run query: %w", err)
 	}
@@ -94,7 +94,7 @@ func (r *repository) GetAll(ctx context.Context) ([]*City, error) {
 		return nil, fmt.Errorf("failed to build query: %w", err)
 	}
 
-	rows, err := r.cluster.ReadRR().Query(ctx, selectQuery, selectArgs...)
+	rows, err := r.cluster.ReadRR(ctx).Query(ctx, selectQuery, selectArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run query: %w", err)
 	}
@@ -129,7 +129,7 @@ func (r *repository) GetByID(ctx context.Context, id int16) (*City, error) {
 
 	var city City
 
-	err = r.cluster.ReadRR().QueryRow(ctx, query, args...).Scan(&city.ID, &city.Name)
+	err = r.cluster.ReadRR(ctx).QueryRow(ctx, query, args...).Scan(&city.ID, &city.Name)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -164,7 +164,7 @@ func (r *repository) GetList(ctx context.Context,
 		return nil, fmt.Errorf("failed to build select query: %w", err)
 	}
 
-	rows, err := r.cluster.ReadRR().Query(ctx, selectQuery, selectArgs...)
+	rows, err := r.cluster.ReadRR(ctx).Query(ctx, selectQuery, selectArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run select query: %w", err)
 	}
@@ -0,0 +1,24 @@
+package counter
+
+type (
+	// Counter represents the number of unread messages in a dialog for one of its participants.
+	Counter struct {
+		UserID            int64 // ID of the user who received the messages.
+		PartnerID         int64 // ID of the other participant of the dialog.
+		UnreadCount       int64 // Number of unread messages.
+		LastReadMessageID int64 // ID of the last message read by the user.
+	}
+
+	// GetListRequest contains parameters for fetching a list of all counters.
+	GetListRequest struct {
+		Limit           uint64 // Maximum number of counters to return.
+		CursorUserID    int64  // User ID of the last counter from the previous page of results.
+		CursorPartnerID int64  // Partner ID of the last counter from the previous page of results.
+	}
+
+	// GetListResponse contains a list of counters and a boolean flag indicating whether there are more counters available.
+	GetListResponse struct {
+		Items   []*Counter // List of counters returned from the query.
+		HasNext bool       // True if there are more counters to fetch, false otherwise.
+	}
+)
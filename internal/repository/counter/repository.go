@@ -0,0 +1,286 @@
+// Package counter provides an interface and implementation of methods for interacting with unread message counters.
+package counter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/oshokin/hive-backend/internal/db"
+)
+
+type (
+	// Repository defines the interface for interacting with the unread_counters table.
+	Repository interface {
+		// Increment increases the number of unread messages from the partner by one.
+		Increment(ctx context.Context, userID, partnerID int64) error
+
+		// CreateMissing creates empty counters for dialogs that have received messages but no counter.
+		// Returns the number of created counters.
+		CreateMissing(ctx context.Context) (int64, error)
+
+		// GetByUserID returns all non-zero counters of the user.
+		GetByUserID(ctx context.Context, userID int64) ([]*Counter, error)
+
+		// GetByUserAndPartner returns the counter of the dialog between the user and the partner.
+		GetByUserAndPartner(ctx context.Context, userID, partnerID int64) (*Counter, error)
+
+		// GetList returns a paginated list of all counters ordered by user and partner IDs.
+		GetList(ctx context.Context, req *GetListRequest) (*GetListResponse, error)
+
+		// MarkRead moves the last read message ID of the counter forward and decreases the number of unread messages.
+		// The counter is changed only if it wasn't changed since it was read.
+		// Returns false if the counter wasn't changed.
+		MarkRead(ctx context.Context, c *Counter, lastReadMessageID, readCount int64) (bool, error)
+
+		// SetUnreadCount overwrites the number of unread messages.
+		// The counter is changed only if it wasn't changed since it was read.
+		// Returns false if the counter wasn't changed.
+		SetUnreadCount(ctx context.Context, c *Counter, unreadCount int64) (bool, error)
+	}
+
+	repository struct {
+		cluster *db.Cluster
+	}
+)
+
+const (
+	tableName               = "unread_counters"
+	columnUserID            = "user_id"
+	columnPartnerID         = "partner_id"
+	columnUnreadCount       = "unread_count"
+	columnLastReadMessageID = "last_read_message_id"
+	columnUpdatedAt         = "updated_at"
+
+	messagesTableName        = "messages"
+	messagesColumnSenderID   = "sender_id"
+	messagesColumnReceiverID = "receiver_id"
+)
+
+// NewRepository creates a new Repository instance with the given database cluster.
+func NewRepository(cluster *db.Cluster) Repository {
+	return &repository{
+		cluster: cluster,
+	}
+}
+
+func (r *repository) Increment(ctx context.Context, userID, partnerID int64) error {
+	query, args, err := sq.Insert(tableName).
+		Columns(columnUserID, columnPartnerID, columnUnreadCount).
+		Values(userID, partnerID, 1).
+		Suffix(fmt.Sprintf("ON CONFLICT (%[1]s, %[2]s) DO UPDATE SET %[3]s = %[4]s.%[3]s + 1, %[5]s = now()",
+			columnUserID,
+			columnPartnerID,
+			columnUnreadCount,
+			tableName,
+			columnUpdatedAt)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = r.cluster.Write().Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	r.cluster.TrackWrite(ctx)
+
+	return nil
+}
+
+func (r *repository) CreateMissing(ctx context.Context) (int64, error) {
+	missingQB := sq.Select(messagesColumnReceiverID, messagesColumnSenderID).
+		Distinct().
+		From(messagesTableName).
+		Where(fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s.%[2]s = %[3]s.%[4]s AND %[1]s.%[5]s = %[3]s.%[6]s)",
+			tableName,
+			columnUserID,
+			messagesTableName,
+			messagesColumnReceiverID,
+			columnPartnerID,
+			messagesColumnSenderID))
+
+	query, args, err := sq.Insert(tableName).
+		Columns(columnUserID, columnPartnerID).
+		Select(missingQB).
+		Suffix(fmt.Sprintf("ON CONFLICT (%s, %s) DO NOTHING", columnUserID, columnPartnerID)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	commandTag, err := r.cluster.Write().Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if commandTag.RowsAffected() > 0 {
+		r.cluster.TrackWrite(ctx)
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+func (r *repository) GetByUserID(ctx context.Context, userID int64) ([]*Counter, error) {
+	sortByPartnerID := fmt.Sprintf("%s ASC", columnPartnerID)
+
+	query, args, err := r.selectDefaultCounterFields().
+		Where(sq.Eq{columnUserID: userID}).
+		Where(sq.Gt{columnUnreadCount: 0}).
+		OrderBy(sortByPartnerID).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.cluster.ReadRR(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run query: %w", err)
+	}
+	defer rows.Close()
+
+	var counters []*Counter
+
+	for rows.Next() {
+		var c Counter
+
+		err = rows.Scan(&c.UserID, &c.PartnerID, &c.UnreadCount, &c.LastReadMessageID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read query results: %w", err)
+		}
+
+		counters = append(counters, &c)
+	}
+
+	return counters, nil
+}
+
+func (r *repository) GetByUserAndPartner(ctx context.Context, userID, partnerID int64) (*Counter, error) {
+	query, args, err := r.selectDefaultCounterFields().
+		Where(sq.Eq{
+			columnUserID:    userID,
+			columnPartnerID: partnerID,
+		}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var c Counter
+
+	err = r.cluster.Write().QueryRow(ctx, query, args...).
+		Scan(&c.UserID, &c.PartnerID, &c.UnreadCount, &c.LastReadMessageID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read query results: %w", err)
+	}
+
+	return &c, nil
+}
+
+func (r *repository) GetList(ctx context.Context,
+	req *GetListRequest) (*GetListResponse, error) {
+	var (
+		sortByUserID    = fmt.Sprintf("%s ASC", columnUserID)
+		sortByPartnerID = fmt.Sprintf("%s ASC", columnPartnerID)
+	)
+
+	selectQB := r.selectDefaultCounterFields().
+		OrderBy(sortByUserID, sortByPartnerID).
+		Limit(req.Limit + 1)
+	if req.CursorUserID != 0 || req.CursorPartnerID != 0 {
+		selectQB = selectQB.Where(fmt.Sprintf("(%s, %s) > (?, ?)", columnUserID, columnPartnerID),
+			req.CursorUserID,
+			req.CursorPartnerID)
+	}
+
+	selectQuery, selectArgs, err := selectQB.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	rows, err := r.cluster.Write().Query(ctx, selectQuery, selectArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run select query: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		counters []*Counter
+		hasNext  bool
+	)
+
+	for rows.Next() {
+		if uint64(len(counters)) >= req.Limit {
+			hasNext = true
+			break
+		}
+
+		var c Counter
+
+		err = rows.Scan(&c.UserID, &c.PartnerID, &c.UnreadCount, &c.LastReadMessageID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read select query results: %w", err)
+		}
+
+		counters = append(counters, &c)
+	}
+
+	return &GetListResponse{
+		Items:   counters,
+		HasNext: hasNext,
+	}, nil
+}
+
+func (r *repository) MarkRead(ctx context.Context, c *Counter, lastReadMessageID, readCount int64) (bool, error) {
+	return r.updateIfUnchanged(ctx, c, sq.Update(tableName).
+		Set(columnLastReadMessageID, lastReadMessageID).
+		Set(columnUnreadCount, sq.Expr(fmt.Sprintf("GREATEST(%s - ?, 0)", columnUnreadCount), readCount)))
+}
+
+func (r *repository) SetUnreadCount(ctx context.Context, c *Counter, unreadCount int64) (bool, error) {
+	return r.updateIfUnchanged(ctx, c, sq.Update(tableName).
+		Set(columnUnreadCount, unreadCount))
+}
+
+func (r *repository) updateIfUnchanged(ctx context.Context, c *Counter, updateBuilder sq.UpdateBuilder) (bool, error) {
+	query, args, err := updateBuilder.
+		Set(columnUpdatedAt, sq.Expr("now()")).
+		Where(sq.Eq{
+			columnUserID:            c.UserID,
+			columnPartnerID:         c.PartnerID,
+			columnUnreadCount:       c.UnreadCount,
+			columnLastReadMessageID: c.LastReadMessageID,
+		}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	commandTag, err := r.cluster.Write().Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	r.cluster.TrackWrite(ctx)
+
+	return commandTag.RowsAffected() > 0, nil
+}
+
+func (r *repository) selectDefaultCounterFields() sq.SelectBuilder {
+	return sq.Select(columnUserID,
+		columnPartnerID,
+		columnUnreadCount,
+		columnLastReadMessageID).
+		From(tableName).
+		PlaceholderFormat(sq.Dollar)
+}
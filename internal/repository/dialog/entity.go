@@ -0,0 +1,28 @@
+package dialog
+
+import "time"
+
+type (
+	// Message represents a message entity in the database.
+	Message struct {
+		DialogID   string    // ID of the dialog the message belongs to.
+		ID         int64     // Unique identifier of the message.
+		SenderID   int64     // ID of the user who sent the message.
+		ReceiverID int64     // ID of the user who received the message.
+		Text       string    // Text of the message.
+		CreatedAt  time.Time // Time when the message was sent.
+	}
+
+	// GetListRequest contains parameters for fetching a list of messages in a dialog.
+	GetListRequest struct {
+		DialogID string // ID of the dialog.
+		Limit    uint64 // Maximum number of messages to return.
+		Cursor   int64  // ID of the last message from the previous page of results.
+	}
+
+	// GetListResponse contains a list of messages and a boolean flag indicating whether there are more messages available.
+	GetListResponse struct {
+		Items   []*Message // List of messages ordered from the newest to the oldest.
+		HasNext bool       // True if there are more messages to fetch, false otherwise.
+	}
+)
@@ -0,0 +1,201 @@
+// Package dialog provides an interface and implementation of methods for interacting with a message database.
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oshokin/hive-backend/internal/db"
+)
+
+type (
+	// Repository defines the interface for interacting with the messages table.
+	// Every query is filtered by the dialog ID, so the table can be sharded by it.
+	Repository interface {
+		// Create creates a new message in the database.
+		// Returns the ID of the newly created message.
+		Create(ctx context.Context, m *Message) (int64, error)
+
+		// CountReceived returns the number of messages in the dialog received by the user
+		// with IDs in the range (afterID, upToID]. Zero upToID means there is no upper bound.
+		CountReceived(ctx context.Context, dialogID string, receiverID, afterID, upToID int64) (int64, error)
+
+		// GetLastMessageID returns the ID of the newest message in the dialog or 0 if the dialog is empty.
+		GetLastMessageID(ctx context.Context, dialogID string) (int64, error)
+
+		// GetList returns a paginated list of messages in the dialog.
+		GetList(ctx context.Context, req *GetListRequest) (*GetListResponse, error)
+	}
+
+	repository struct {
+		cluster *db.Cluster
+	}
+)
+
+const (
+	tableName        = "messages"
+	columnDialogID   = "dialog_id"
+	columnID         = "id"
+	columnSenderID   = "sender_id"
+	columnReceiverID = "receiver_id"
+	columnText       = "text"
+	columnCreatedAt  = "created_at"
+)
+
+// GetDialogID returns the ID of the dialog between two users.
+// The ID doesn't depend on the order of the users and is used as a sharding key.
+func GetDialogID(firstUserID, secondUserID int64) string {
+	if firstUserID > secondUserID {
+		firstUserID, secondUserID = secondUserID, firstUserID
+	}
+
+	return strings.Join([]string{
+		strconv.FormatInt(firstUserID, 10),
+		strconv.FormatInt(secondUserID, 10),
+	}, ":")
+}
+
+// NewRepository creates a new Repository instance with the given database cluster.
+func NewRepository(cluster *db.Cluster) Repository {
+	return &repository{
+		cluster: cluster,
+	}
+}
+
+func (r *repository) Create(ctx context.Context, m *Message) (int64, error) {
+	query, args, err := sq.Insert(tableName).
+		Columns(columnDialogID, columnSenderID, columnReceiverID, columnText).
+		Values(m.DialogID, m.SenderID, m.ReceiverID, m.Text).
+		Suffix(fmt.Sprintf("RETURNING %s, %s", columnID, columnCreatedAt)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.cluster.Write().QueryRow(ctx, query, args...).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read query results: %w", err)
+	}
+
+	r.cluster.TrackWrite(ctx)
+
+	return m.ID, nil
+}
+
+func (r *repository) CountReceived(ctx context.Context,
+	dialogID string,
+	receiverID, afterID, upToID int64) (int64, error) {
+	selectQB := sq.Select("COUNT(*)").
+		From(tableName).
+		Where(sq.Eq{
+			columnDialogID:   dialogID,
+			columnReceiverID: receiverID,
+		}).
+		Where(sq.Gt{columnID: afterID}).
+		PlaceholderFormat(sq.Dollar)
+	if upToID != 0 {
+		selectQB = selectQB.Where(sq.LtOrEq{columnID: upToID})
+	}
+
+	query, args, err := selectQB.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var count int64
+
+	// Counters are fixed up using this number, so it's read from the master to avoid replication lag.
+	err = r.cluster.Write().QueryRow(ctx, query, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read query results: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *repository) GetLastMessageID(ctx context.Context, dialogID string) (int64, error) {
+	query, args, err := sq.Select(fmt.Sprintf("COALESCE(MAX(%s), 0)", columnID)).
+		From(tableName).
+		Where(sq.Eq{columnDialogID: dialogID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var id int64
+
+	err = r.cluster.Write().QueryRow(ctx, query, args...).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read query results: %w", err)
+	}
+
+	return id, nil
+}
+
+func (r *repository) GetList(ctx context.Context,
+	req *GetListRequest) (*GetListResponse, error) {
+	sortByID := fmt.Sprintf("%s DESC", columnID)
+
+	selectQB := sq.StatementBuilder.
+		Select(columnDialogID,
+			columnID,
+			columnSenderID,
+			columnReceiverID,
+			columnText,
+			columnCreatedAt).
+		From(tableName).
+		Where(sq.Eq{columnDialogID: req.DialogID}).
+		OrderBy(sortByID).
+		Limit(req.Limit + 1).
+		PlaceholderFormat(sq.Dollar)
+	if req.Cursor != 0 {
+		selectQB = selectQB.Where(sq.Lt{columnID: req.Cursor})
+	}
+
+	selectQuery, selectArgs, err := selectQB.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	rows, err := r.cluster.ReadRR(ctx).Query(ctx, selectQuery, selectArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run select query: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		messages []*Message
+		hasNext  bool
+	)
+
+	for rows.Next() {
+		if uint64(len(messages)) >= req.Limit {
+			hasNext = true
+			break
+		}
+
+		var m Message
+
+		err = rows.Scan(&m.DialogID,
+			&m.ID,
+			&m.SenderID,
+			&m.ReceiverID,
+			&m.Text,
+			&m.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read select query results: %w", err)
+		}
+
+		messages = append(messages, &m)
+	}
+
+	return &GetListResponse{
+		Items:   messages,
+		HasNext: hasNext,
+	}, nil
+}
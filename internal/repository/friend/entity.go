@@ -0,0 +1,25 @@
+package friend
+
+import "time"
+
+type (
+	// Friendship represents a one-way friendship link between two users.
+	Friendship struct {
+		UserID    int64     // ID of the user who added a friend.
+		FriendID  int64     // ID of the user who was added as a friend.
+		CreatedAt time.Time // Time when the friend was added.
+	}
+
+	// GetListRequest contains parameters for fetching a list of user's friends.
+	GetListRequest struct {
+		UserID int64  // ID of the user whose friends are requested.
+		Limit  uint64 // Maximum number of friends to return.
+		Cursor int64  // ID of the last friend from the previous page of results.
+	}
+
+	// GetListResponse contains a list of friendships and a boolean flag indicating whether there are more items available.
+	GetListResponse struct {
+		Items   []*Friendship // List of friendships returned from the query.
+		HasNext bool          // True if there are more friends to fetch, false otherwise.
+	}
+)
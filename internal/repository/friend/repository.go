@@ -0,0 +1,235 @@
+package friend
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/oshokin/hive-backend/internal/db"
+)
+
+type (
+	// Repository defines the methods for interacting with the friendships data store.
+	Repository interface {
+		// Create adds the friend to the user's friend list.
+		// Returns false if the friend was already in the list.
+		Create(ctx context.Context, userID, friendID int64) (bool, error)
+
+		// Delete removes the friend from the user's friend list.
+		// Returns false if the friend was not in the list.
+		Delete(ctx context.Context, userID, friendID int64) (bool, error)
+
+		// GetFollowerIDs returns IDs of all users who have the given user in their friend lists.
+		GetFollowerIDs(ctx context.Context, friendID int64) ([]int64, error)
+
+		// FilterFollowerIDs returns IDs of the given users who have the given friend in their friend lists.
+		FilterFollowerIDs(ctx context.Context, friendID int64, userIDs []int64) ([]int64, error)
+
+		// GetFriendIDs returns IDs of all friends of the given user.
+		GetFriendIDs(ctx context.Context, userID int64) ([]int64, error)
+
+		// GetList returns a paginated list of the user's friends.
+		GetList(ctx context.Context, req *GetListRequest) (*GetListResponse, error)
+	}
+
+	repository struct {
+		cluster *db.Cluster
+	}
+)
+
+const (
+	tableName       = "friendships"
+	columnUserID    = "user_id"
+	columnFriendID  = "friend_id"
+	columnCreatedAt = "created_at"
+
+	usersTableName       = "users"
+	usersColumnID        = "id"
+	usersColumnDeletedAt = "deleted_at"
+)
+
+// friendNotDeleted excludes deleted users from friend lists, they are kept until the users are purged.
+var friendNotDeleted = sq.Expr(fmt.Sprintf(
+	"EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s.%[2]s = %[3]s.%[4]s AND %[1]s.%[5]s IS NULL)",
+	usersTableName,
+	usersColumnID,
+	tableName,
+	columnFriendID,
+	usersColumnDeletedAt))
+
+// NewRepository creates a new Repository instance with the given database cluster.
+func NewRepository(cluster *db.Cluster) Repository {
+	return &repository{
+		cluster: cluster,
+	}
+}
+
+func (r *repository) Create(ctx context.Context, userID, friendID int64) (bool, error) {
+	query, args, err := sq.Insert(tableName).
+		Columns(columnUserID, columnFriendID).
+		Values(userID, friendID).
+		Suffix("ON CONFLICT DO NOTHING").
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	commandTag, err := r.cluster.Write().Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	r.cluster.TrackWrite(ctx)
+
+	return true, nil
+}
+
+func (r *repository) Delete(ctx context.Context, userID, friendID int64) (bool, error) {
+	query, args, err := sq.Delete(tableName).
+		Where(sq.Eq{
+			columnUserID:   userID,
+			columnFriendID: friendID,
+		}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	commandTag, err := r.cluster.Write().Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	r.cluster.TrackWrite(ctx)
+
+	return true, nil
+}
+
+func (r *repository) GetFollowerIDs(ctx context.Context, friendID int64) ([]int64, error) {
+	query, args, err := sq.Select(columnUserID).
+		From(tableName).
+		Where(sq.Eq{columnFriendID: friendID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	return r.selectIDs(ctx, query, args...)
+}
+
+func (r *repository) FilterFollowerIDs(ctx context.Context, friendID int64, userIDs []int64) ([]int64, error) {
+	query, args, err := sq.Select(columnUserID).
+		From(tableName).
+		Where(sq.Eq{
+			columnUserID:   userIDs,
+			columnFriendID: friendID,
+		}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	return r.selectIDs(ctx, query, args...)
+}
+
+func (r *repository) GetFriendIDs(ctx context.Context, userID int64) ([]int64, error) {
+	query, args, err := sq.Select(columnFriendID).
+		From(tableName).
+		Where(sq.Eq{columnUserID: userID}).
+		Where(friendNotDeleted).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	return r.selectIDs(ctx, query, args...)
+}
+
+func (r *repository) GetList(ctx context.Context,
+	req *GetListRequest) (*GetListResponse, error) {
+	sortByFriendID := fmt.Sprintf("%s ASC", columnFriendID)
+
+	selectQB := sq.StatementBuilder.
+		Select(columnUserID, columnFriendID, columnCreatedAt).
+		From(tableName).
+		Where(sq.Eq{columnUserID: req.UserID}).
+		Where(friendNotDeleted).
+		OrderBy(sortByFriendID).
+		Limit(req.Limit + 1).
+		PlaceholderFormat(sq.Dollar)
+	if req.Cursor != 0 {
+		selectQB = selectQB.Where(sq.Gt{columnFriendID: req.Cursor})
+	}
+
+	selectQuery, selectArgs, err := selectQB.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	rows, err := r.cluster.ReadRR(ctx).Query(ctx, selectQuery, selectArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run select query: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		friendships []*Friendship
+		hasNext     bool
+	)
+
+	for rows.Next() {
+		if uint64(len(friendships)) >= req.Limit {
+			hasNext = true
+			break
+		}
+
+		var f Friendship
+
+		err = rows.Scan(&f.UserID, &f.FriendID, &f.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read select query results: %w", err)
+		}
+
+		friendships = append(friendships, &f)
+	}
+
+	return &GetListResponse{
+		Items:   friendships,
+		HasNext: hasNext,
+	}, nil
+}
+
+func (r *repository) selectIDs(ctx context.Context, query string, args ...any) ([]int64, error) {
+	rows, err := r.cluster.ReadRR(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run query: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+
+		if err = rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to read query results: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,9 @@
+package login_attempt
+
+import "time"
+
+// Attempts represents failed login attempts made with the same key (an email or an IP address).
+type Attempts struct {
+	Failures         int64         // Number of failed attempts in a row.
+	SinceLastFailure time.Duration // Time passed since the last failed attempt.
+}
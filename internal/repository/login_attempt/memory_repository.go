@@ -0,0 +1,99 @@
+package login_attempt
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type (
+	memoryRepository struct {
+		attempts map[string]*memoryAttempts
+		mu       sync.Mutex
+	}
+
+	memoryAttempts struct {
+		failures     int64
+		lastFailedAt time.Time
+	}
+)
+
+// NewMemoryRepository creates a new Repository instance which keeps attempts in memory of the process.
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		attempts: make(map[string]*memoryAttempts),
+	}
+}
+
+func (r *memoryRepository) Get(_ context.Context, key string) (*Attempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a, ok := r.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+
+	return a.toAttempts(), nil
+}
+
+func (r *memoryRepository) RegisterFailure(_ context.Context,
+	key string,
+	resetAfter time.Duration) (*Attempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+
+	a, ok := r.attempts[key]
+	if !ok || now.Sub(a.lastFailedAt) > resetAfter {
+		a = &memoryAttempts{}
+		r.attempts[key] = a
+	}
+
+	a.failures++
+	a.lastFailedAt = now
+
+	return a.toAttempts(), nil
+}
+
+func (r *memoryRepository) ReleaseFailure(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if a, ok := r.attempts[key]; ok && a.failures > 0 {
+		a.failures--
+	}
+
+	return nil
+}
+
+func (r *memoryRepository) Delete(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+
+	return nil
+}
+
+func (r *memoryRepository) DeleteOlderThan(_ context.Context, age time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for key, a := range r.attempts {
+		if now.Sub(a.lastFailedAt) > age {
+			delete(r.attempts, key)
+		}
+	}
+
+	return nil
+}
+
+func (a *memoryAttempts) toAttempts() *Attempts {
+	return &Attempts{
+		Failures:         a.failures,
+		SinceLastFailure: time.Since(a.lastFailedAt),
+	}
+}
@@ -0,0 +1,168 @@
+// Package login_attempt provides an interface and implementations of storages of failed login attempts.
+package login_attempt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/oshokin/hive-backend/internal/db"
+)
+
+type (
+	// Repository defines the interface for storing failed login attempts.
+	Repository interface {
+		// Get returns failed attempts made with the key or nil if there are none.
+		Get(ctx context.Context, key string) (*Attempts, error)
+
+		// RegisterFailure counts a failed attempt made with the key.
+		// The counter starts over if the previous failure happened more than resetAfter ago.
+		RegisterFailure(ctx context.Context, key string, resetAfter time.Duration) (*Attempts, error)
+
+		// ReleaseFailure takes back one failed attempt made with the key, which turned out to be successful.
+		ReleaseFailure(ctx context.Context, key string) error
+
+		// Delete forgets failed attempts made with the key.
+		Delete(ctx context.Context, key string) error
+
+		// DeleteOlderThan forgets failed attempts whose last failure happened more than age ago.
+		DeleteOlderThan(ctx context.Context, age time.Duration) error
+	}
+
+	repository struct {
+		cluster *db.Cluster
+	}
+)
+
+const (
+	tableName          = "login_attempts"
+	columnKey          = "key"
+	columnFailures     = "failures"
+	columnLastFailedAt = "last_failed_at"
+)
+
+// NewRepository creates a new Repository instance which keeps attempts in the master database,
+// so that all instances of the application share them.
+func NewRepository(cluster *db.Cluster) Repository {
+	return &repository{
+		cluster: cluster,
+	}
+}
+
+func (r *repository) Get(ctx context.Context, key string) (*Attempts, error) {
+	query, args, err := sq.Select(columnFailures,
+		fmt.Sprintf("extract(epoch FROM now() - %s)::float8", columnLastFailedAt)).
+		From(tableName).
+		Where(sq.Eq{columnKey: key}).
+		Limit(1).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	a, err := scanAttempts(r.cluster.Write().QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read query results: %w", err)
+	}
+
+	return a, nil
+}
+
+func (r *repository) RegisterFailure(ctx context.Context, key string, resetAfter time.Duration) (*Attempts, error) {
+	query, args, err := sq.Insert(tableName).
+		Columns(columnKey, columnFailures, columnLastFailedAt).
+		Values(key, 1, sq.Expr("now()")).
+		Suffix(fmt.Sprintf("ON CONFLICT (%[1]s) DO UPDATE SET "+
+			"%[2]s = CASE WHEN %[4]s.%[3]s < now() - make_interval(secs => ?) THEN 1 ELSE %[4]s.%[2]s + 1 END, "+
+			"%[3]s = now() "+
+			"RETURNING %[2]s, 0::float8",
+			columnKey,
+			columnFailures,
+			columnLastFailedAt,
+			tableName), resetAfter.Seconds()).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	a, err := scanAttempts(r.cluster.Write().QueryRow(ctx, query, args...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query results: %w", err)
+	}
+
+	return a, nil
+}
+
+func (r *repository) ReleaseFailure(ctx context.Context, key string) error {
+	query, args, err := sq.Update(tableName).
+		Set(columnFailures, sq.Expr(fmt.Sprintf("%s - 1", columnFailures))).
+		Where(sq.Eq{columnKey: key}).
+		Where(sq.Gt{columnFailures: 0}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = r.cluster.Write().Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) Delete(ctx context.Context, key string) error {
+	query, args, err := sq.Delete(tableName).
+		Where(sq.Eq{columnKey: key}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = r.cluster.Write().Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) DeleteOlderThan(ctx context.Context, age time.Duration) error {
+	query, args, err := sq.Delete(tableName).
+		Where(sq.Expr(fmt.Sprintf("%s < now() - make_interval(secs => ?)", columnLastFailedAt), age.Seconds())).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = r.cluster.Write().Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func scanAttempts(row pgx.Row) (*Attempts, error) {
+	var (
+		a                       Attempts
+		secondsSinceLastFailure float64
+	)
+
+	if err := row.Scan(&a.Failures, &secondsSinceLastFailure); err != nil {
+		return nil, err
+	}
+
+	a.SinceLastFailure = time.Duration(secondsSinceLastFailure * float64(time.Second))
+
+	return &a, nil
+}
@@ -0,0 +1,10 @@
+package password_reset
+
+import "time"
+
+// Token represents a password reset token entity in the database.
+type Token struct {
+	TokenHash string    // SHA-256 hash of the token, the token itself is never stored.
+	UserID    int64     // ID of the user whose password can be reset with the token.
+	ExpiresAt time.Time // Time when the token expires.
+}
@@ -0,0 +1,108 @@
+// Package password_reset provides an interface and implementation of methods for interacting with password reset tokens.
+package password_reset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/oshokin/hive-backend/internal/db"
+)
+
+type (
+	// Repository defines the interface for interacting with the password_reset_tokens table.
+	Repository interface {
+		// Create creates a new password reset token.
+		Create(ctx context.Context, t *Token) error
+
+		// Consume marks the token as used if it's neither used nor expired.
+		// Returns the ID of the token's user or 0 if the token can't be used.
+		Consume(ctx context.Context, tokenHash string) (int64, error)
+
+		// DeleteByUserID deletes all tokens of the user.
+		DeleteByUserID(ctx context.Context, userID int64) error
+	}
+
+	repository struct {
+		cluster *db.Cluster
+	}
+)
+
+const (
+	tableName       = "password_reset_tokens"
+	columnTokenHash = "token_hash"
+	columnUserID    = "user_id"
+	columnExpiresAt = "expires_at"
+	columnUsedAt    = "used_at"
+)
+
+// NewRepository creates a new Repository instance with the given database cluster.
+func NewRepository(cluster *db.Cluster) Repository {
+	return &repository{
+		cluster: cluster,
+	}
+}
+
+func (r *repository) Create(ctx context.Context, t *Token) error {
+	query, args, err := sq.Insert(tableName).
+		Columns(columnTokenHash, columnUserID, columnExpiresAt).
+		Values(t.TokenHash, t.UserID, t.ExpiresAt).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = r.cluster.Write().Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) Consume(ctx context.Context, tokenHash string) (int64, error) {
+	query, args, err := sq.Update(tableName).
+		Set(columnUsedAt, sq.Expr("now()")).
+		Where(sq.Eq{
+			columnTokenHash: tokenHash,
+			columnUsedAt:    nil,
+		}).
+		Where(sq.Expr(fmt.Sprintf("%s > now()", columnExpiresAt))).
+		Suffix(fmt.Sprintf("RETURNING %s", columnUserID)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var userID int64
+
+	err = r.cluster.Write().QueryRow(ctx, query, args...).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("failed to read query results: %w", err)
+	}
+
+	return userID, nil
+}
+
+func (r *repository) DeleteByUserID(ctx context.Context, userID int64) error {
+	query, args, err := sq.Delete(tableName).
+		Where(sq.Eq{columnUserID: userID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = r.cluster.Write().Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package post
+
+import "time"
+
+type (
+	// Post represents a post entity in the database.
+	Post struct {
+		ID        int64      // Unique identifier of the post.
+		AuthorID  int64      // ID of the user who wrote the post.
+		Text      string     // Text of the post.
+		CreatedAt time.Time  // Time when the post was created.
+		UpdatedAt *time.Time // Time when the post was last updated (nil if it was never updated).
+	}
+
+	// UpdateFields defines which fields of the post must be updated.
+	UpdateFields struct {
+		Text      bool
+		UpdatedAt bool
+	}
+)
@@ -0,0 +1,232 @@
+// Package post provides an interface and implementation of methods for interacting with a post database.
+package post
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/oshokin/hive-backend/internal/db"
+)
+
+type (
+	// Repository defines the interface for interacting with the posts table.
+	Repository interface {
+		// Create creates a new post in the database.
+		// Returns the ID of the newly created post.
+		Create(ctx context.Context, p *Post) (int64, error)
+
+		// Delete deletes the post with the given ID.
+		// Returns false if there was no such post.
+		Delete(ctx context.Context, id int64) (bool, error)
+
+		// GetByID returns the post with the given ID.
+		GetByID(ctx context.Context, id int64) (*Post, error)
+
+		// GetLatestByAuthorIDs returns the latest posts written by the given authors,
+		// ordered from the newest to the oldest.
+		GetLatestByAuthorIDs(ctx context.Context, authorIDs []int64, limit uint64) ([]*Post, error)
+
+		// Update updates the given fields of the post.
+		Update(ctx context.Context, p *Post, fields *UpdateFields) error
+	}
+
+	repository struct {
+		cluster *db.Cluster
+	}
+)
+
+const (
+	tableName       = "posts"
+	columnID        = "id"
+	columnAuthorID  = "author_id"
+	columnText      = "text"
+	columnCreatedAt = "created_at"
+	columnUpdatedAt = "updated_at"
+
+	usersTableName       = "users"
+	usersColumnID        = "id"
+	usersColumnDeletedAt = "deleted_at"
+)
+
+// authorNotDeleted excludes posts of deleted users, they are kept until the users are purged.
+var authorNotDeleted = sq.Expr(fmt.Sprintf(
+	"EXISTS (SELECT 1 FROM %[1]s WHERE %[1]s.%[2]s = %[3]s.%[4]s AND %[1]s.%[5]s IS NULL)",
+	usersTableName,
+	usersColumnID,
+	tableName,
+	columnAuthorID,
+	usersColumnDeletedAt))
+
+// NewRepository creates a new Repository instance with the given database cluster.
+func NewRepository(cluster *db.Cluster) Repository {
+	return &repository{
+		cluster: cluster,
+	}
+}
+
+func (r *repository) Create(ctx context.Context, p *Post) (int64, error) {
+	query, args, err := sq.Insert(tableName).
+		Columns(columnAuthorID, columnText).
+		Values(p.AuthorID, p.Text).
+		Suffix(fmt.Sprintf("RETURNING %s, %s", columnID, columnCreatedAt)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	err = r.cluster.Write().QueryRow(ctx, query, args...).Scan(&p.ID, &p.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read query results: %w", err)
+	}
+
+	r.cluster.TrackWrite(ctx)
+
+	return p.ID, nil
+}
+
+func (r *repository) Delete(ctx context.Context, id int64) (bool, error) {
+	query, args, err := sq.Delete(tableName).
+		Where(sq.Eq{columnID: id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	commandTag, err := r.cluster.Write().Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	r.cluster.TrackWrite(ctx)
+
+	return true, nil
+}
+
+func (r *repository) GetByID(ctx context.Context, id int64) (*Post, error) {
+	query, args, err := sq.Select(columnID,
+		columnAuthorID,
+		columnText,
+		columnCreatedAt,
+		columnUpdatedAt).
+		From(tableName).
+		Where(sq.Eq{columnID: id}).
+		Where(authorNotDeleted).
+		Limit(1).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var p Post
+
+	err = r.cluster.ReadRR(ctx).QueryRow(ctx, query, args...).
+		Scan(&p.ID,
+			&p.AuthorID,
+			&p.Text,
+			&p.CreatedAt,
+			&p.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read query results: %w", err)
+	}
+
+	return &p, nil
+}
+
+func (r *repository) GetLatestByAuthorIDs(ctx context.Context, authorIDs []int64, limit uint64) ([]*Post, error) {
+	if len(authorIDs) == 0 {
+		return nil, nil
+	}
+
+	sortByID := fmt.Sprintf("%s DESC", columnID)
+
+	query, args, err := sq.Select(columnID,
+		columnAuthorID,
+		columnText,
+		columnCreatedAt,
+		columnUpdatedAt).
+		From(tableName).
+		Where(sq.Eq{columnAuthorID: authorIDs}).
+		Where(authorNotDeleted).
+		OrderBy(sortByID).
+		Limit(limit).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := r.cluster.ReadRR(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run query: %w", err)
+	}
+	defer rows.Close()
+
+	posts := make([]*Post, 0, limit)
+
+	for rows.Next() {
+		var p Post
+
+		err = rows.Scan(&p.ID,
+			&p.AuthorID,
+			&p.Text,
+			&p.CreatedAt,
+			&p.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read query results: %w", err)
+		}
+
+		posts = append(posts, &p)
+	}
+
+	return posts, nil
+}
+
+func (r *repository) Update(ctx context.Context, p *Post, fields *UpdateFields) error {
+	if fields == nil {
+		return nil
+	}
+
+	updateBuilder := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{columnID: p.ID})
+
+	if fields.Text {
+		updateBuilder = updateBuilder.Set(columnText, p.Text)
+	}
+
+	if fields.UpdatedAt {
+		updateBuilder = updateBuilder.Set(columnUpdatedAt, p.UpdatedAt)
+	}
+
+	query, args, err := updateBuilder.ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	commandTag, err := r.cluster.Write().Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("no rows updated")
+	}
+
+	r.cluster.TrackWrite(ctx)
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package session
+
+import "time"
+
+// Session represents an issued refresh token.
+// Every refresh token belongs to a family started at login,
+// rotation replaces the token with a new one of the same family.
+type Session struct {
+	ID         string     // ID of the refresh token (jti claim).
+	FamilyID   string     // ID of the token family.
+	UserID     int64      // ID of the user the token was issued to.
+	CreatedAt  time.Time  // Date and time when the token was issued.
+	ExpiresAt  time.Time  // Date and time when the token expires.
+	RotatedAt  *time.Time // Date and time when the token was exchanged for a new one (nil if it's still active).
+	LastSeenAt time.Time  // Date and time of the last request made with the token.
+	UserAgent  string     // User agent of the client the token was issued to.
+	IP         string     // IP address of the client the token was issued to.
+}
@@ -0,0 +1,181 @@
+package session
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/oshokin/hive-backend/internal/logger"
+)
+
+type memoryStore struct {
+	sessions map[string]*Session
+	mu       sync.RWMutex
+	cancel   context.CancelFunc
+	cancelMu sync.Mutex
+}
+
+// NewMemoryStore creates a new SessionStore which keeps sessions in memory of the process.
+// Sessions are lost on restart and aren't shared between instances of the application.
+func NewMemoryStore() SessionStore {
+	return &memoryStore{
+		sessions: make(map[string]*Session),
+	}
+}
+
+func (m *memoryStore) Start(ctx context.Context) {
+	logger.Info(ctx, "starting in-memory session store")
+
+	m.cancelMu.Lock()
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.cancelMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(sweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.deleteExpired()
+			}
+		}
+	}()
+
+	logger.Info(ctx, "in-memory session store is running")
+}
+
+func (m *memoryStore) Stop(ctx context.Context) {
+	logger.Info(ctx, "shutting down in-memory session store")
+	m.cancelMu.Lock()
+
+	if m.cancel != nil {
+		m.cancel()
+	}
+
+	m.cancelMu.Unlock()
+	logger.Info(ctx, "in-memory session store stopped")
+}
+
+func (m *memoryStore) Create(_ context.Context, s *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := *s
+	m.sessions[s.ID] = &c
+
+	return nil
+}
+
+func (m *memoryStore) GetByID(_ context.Context, id string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, isFound := m.sessions[id]
+	if !isFound || !s.ExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+
+	c := *s
+
+	return &c, nil
+}
+
+func (m *memoryStore) GetActiveByUserID(_ context.Context, userID int64) ([]*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var (
+		now      = time.Now()
+		sessions []*Session
+	)
+
+	for _, s := range m.sessions {
+		if s.UserID != userID || s.RotatedAt != nil || !s.ExpiresAt.After(now) {
+			continue
+		}
+
+		c := *s
+		sessions = append(sessions, &c)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+
+	return sessions, nil
+}
+
+func (m *memoryStore) Touch(_ context.Context, id string, lastSeenAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s, isFound := m.sessions[id]; isFound {
+		s.LastSeenAt = lastSeenAt
+	}
+
+	return nil
+}
+
+func (m *memoryStore) Rotate(_ context.Context, id string, next *Session) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, isFound := m.sessions[id]
+	if !isFound || s.RotatedAt != nil {
+		return false, nil
+	}
+
+	rotatedAt := time.Now()
+	s.RotatedAt = &rotatedAt
+
+	c := *next
+	m.sessions[next.ID] = &c
+
+	return true, nil
+}
+
+func (m *memoryStore) DeleteFamily(_ context.Context, userID int64, familyID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var isDeleted bool
+
+	for id, s := range m.sessions {
+		if s.UserID == userID && s.FamilyID == familyID {
+			delete(m.sessions, id)
+
+			isDeleted = true
+		}
+	}
+
+	return isDeleted, nil
+}
+
+func (m *memoryStore) DeleteByUserID(_ context.Context, userID int64, exceptFamilyID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, s := range m.sessions {
+		if s.UserID == userID && s.FamilyID != exceptFamilyID {
+			delete(m.sessions, id)
+		}
+	}
+
+	return nil
+}
+
+func (m *memoryStore) deleteExpired() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for id, s := range m.sessions {
+		if !s.ExpiresAt.After(now) {
+			delete(m.sessions, id)
+		}
+	}
+}
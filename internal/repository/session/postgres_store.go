@@ -0,0 +1,311 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/oshokin/hive-backend/internal/common"
+	"github.com/oshokin/hive-backend/internal/db"
+	"github.com/oshokin/hive-backend/internal/logger"
+)
+
+type postgresStore struct {
+	cluster *db.Cluster
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+}
+
+const (
+	tableName        = "sessions"
+	columnID         = "id"
+	columnFamilyID   = "family_id"
+	columnUserID     = "user_id"
+	columnCreatedAt  = "created_at"
+	columnExpiresAt  = "expires_at"
+	columnRotatedAt  = "rotated_at"
+	columnLastSeenAt = "last_seen_at"
+	columnUserAgent  = "user_agent"
+	columnIP         = "ip"
+)
+
+// NewPostgresStore creates a new SessionStore which keeps sessions in the sessions table.
+// All queries go to the master, so that a rotated token can't be reused via a lagging replica.
+func NewPostgresStore(cluster *db.Cluster) SessionStore {
+	return &postgresStore{
+		cluster: cluster,
+	}
+}
+
+func (p *postgresStore) Start(ctx context.Context) {
+	logger.Info(ctx, "starting postgres session store")
+
+	p.mu.Lock()
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(sweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := p.deleteExpired(ctx); err != nil {
+					logger.ErrorKV(ctx, "failed to delete expired sessions", common.ErrorTag, err)
+				}
+			}
+		}
+	}()
+
+	logger.Info(ctx, "postgres session store is running")
+}
+
+func (p *postgresStore) Stop(ctx context.Context) {
+	logger.Info(ctx, "shutting down postgres session store")
+	p.mu.Lock()
+
+	if p.cancel != nil {
+		p.cancel()
+	}
+
+	p.mu.Unlock()
+	logger.Info(ctx, "postgres session store stopped")
+}
+
+func (p *postgresStore) Create(ctx context.Context, s *Session) error {
+	return p.create(ctx, p.cluster.Write(), s)
+}
+
+func (p *postgresStore) GetByID(ctx context.Context, id string) (*Session, error) {
+	query, args, err := p.selectDefaultSessionFields().
+		Where(sq.Eq{columnID: id}).
+		Where(sq.Expr(fmt.Sprintf("%s > now()", columnExpiresAt))).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	s, err := scanSession(p.cluster.Write().QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to read query results: %w", err)
+	}
+
+	return s, nil
+}
+
+func (p *postgresStore) GetActiveByUserID(ctx context.Context, userID int64) ([]*Session, error) {
+	sortByLastSeenAt := fmt.Sprintf("%s DESC", columnLastSeenAt)
+
+	query, args, err := p.selectDefaultSessionFields().
+		Where(sq.Eq{
+			columnUserID:    userID,
+			columnRotatedAt: nil,
+		}).
+		Where(sq.Expr(fmt.Sprintf("%s > now()", columnExpiresAt))).
+		OrderBy(sortByLastSeenAt).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := p.cluster.Write().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run query: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*Session
+
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read query results: %w", err)
+		}
+
+		sessions = append(sessions, s)
+	}
+
+	return sessions, nil
+}
+
+func (p *postgresStore) Touch(ctx context.Context, id string, lastSeenAt time.Time) error {
+	query, args, err := sq.Update(tableName).
+		Set(columnLastSeenAt, lastSeenAt).
+		Where(sq.Eq{columnID: id}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = p.cluster.Write().Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (p *postgresStore) Rotate(ctx context.Context, id string, next *Session) (bool, error) {
+	query, args, err := sq.Update(tableName).
+		Set(columnRotatedAt, sq.Expr("now()")).
+		Where(sq.Eq{
+			columnID:        id,
+			columnRotatedAt: nil,
+		}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var isRotated bool
+
+	err = pgx.BeginFunc(ctx, p.cluster.Write(), func(tx pgx.Tx) error {
+		commandTag, localErr := tx.Exec(ctx, query, args...)
+		if localErr != nil {
+			return fmt.Errorf("failed to execute query: %w", localErr)
+		}
+
+		if commandTag.RowsAffected() == 0 {
+			return nil
+		}
+
+		isRotated = true
+
+		return p.create(ctx, tx, next)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return isRotated, nil
+}
+
+func (p *postgresStore) DeleteFamily(ctx context.Context, userID int64, familyID string) (bool, error) {
+	query, args, err := sq.Delete(tableName).
+		Where(sq.Eq{
+			columnUserID:   userID,
+			columnFamilyID: familyID,
+		}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	commandTag, err := p.cluster.Write().Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return commandTag.RowsAffected() > 0, nil
+}
+
+func (p *postgresStore) DeleteByUserID(ctx context.Context, userID int64, exceptFamilyID string) error {
+	query, args, err := sq.Delete(tableName).
+		Where(sq.Eq{columnUserID: userID}).
+		Where(sq.NotEq{columnFamilyID: exceptFamilyID}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = p.cluster.Write().Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (p *postgresStore) create(ctx context.Context, e db.WriterDB, s *Session) error {
+	query, args, err := sq.Insert(tableName).
+		Columns(columnID,
+			columnFamilyID,
+			columnUserID,
+			columnCreatedAt,
+			columnExpiresAt,
+			columnLastSeenAt,
+			columnUserAgent,
+			columnIP).
+		Values(s.ID,
+			s.FamilyID,
+			s.UserID,
+			s.CreatedAt,
+			s.ExpiresAt,
+			s.LastSeenAt,
+			s.UserAgent,
+			s.IP).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = e.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (p *postgresStore) deleteExpired(ctx context.Context) error {
+	query, args, err := sq.Delete(tableName).
+		Where(sq.Expr(fmt.Sprintf("%s <= now()", columnExpiresAt))).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = p.cluster.Write().Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return nil
+}
+
+func (p *postgresStore) selectDefaultSessionFields() sq.SelectBuilder {
+	return sq.Select(columnID,
+		columnFamilyID,
+		columnUserID,
+		columnCreatedAt,
+		columnExpiresAt,
+		columnRotatedAt,
+		columnLastSeenAt,
+		columnUserAgent,
+		columnIP).
+		From(tableName).
+		PlaceholderFormat(sq.Dollar)
+}
+
+func scanSession(row pgx.Row) (*Session, error) {
+	var s Session
+
+	err := row.Scan(&s.ID,
+		&s.FamilyID,
+		&s.UserID,
+		&s.CreatedAt,
+		&s.ExpiresAt,
+		&s.RotatedAt,
+		&s.LastSeenAt,
+		&s.UserAgent,
+		&s.IP)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,43 @@
+// Package session provides an interface and implementations of storages of refresh token sessions.
+package session
+
+import (
+	"context"
+	"time"
+)
+
+// SessionStore defines the interface for storing refresh token sessions.
+type SessionStore interface {
+	// Start starts the removal of expired sessions.
+	Start(ctx context.Context)
+
+	// Stop stops the removal of expired sessions.
+	Stop(ctx context.Context)
+
+	// Create stores a new session.
+	Create(ctx context.Context, s *Session) error
+
+	// GetByID returns the session by its ID or nil if it doesn't exist or has expired.
+	GetByID(ctx context.Context, id string) (*Session, error)
+
+	// GetActiveByUserID returns active sessions of the user ordered by the last request time (newest first).
+	GetActiveByUserID(ctx context.Context, userID int64) ([]*Session, error)
+
+	// Touch updates the last request time of the session.
+	Touch(ctx context.Context, id string, lastSeenAt time.Time) error
+
+	// Rotate marks the session as rotated and stores the next session of the same family.
+	// Returns false if the session was already rotated or doesn't exist.
+	Rotate(ctx context.Context, id string, next *Session) (bool, error)
+
+	// DeleteFamily deletes all sessions of the user's family.
+	// Returns false if the user has no sessions of the family.
+	DeleteFamily(ctx context.Context, userID int64, familyID string) (bool, error)
+
+	// DeleteByUserID deletes all sessions of the user except the sessions of the given family.
+	// Pass an empty family ID to delete all sessions.
+	DeleteByUserID(ctx context.Context, userID int64, exceptFamilyID string) error
+}
+
+// sweepInterval is the interval between removals of expired sessions.
+const sweepInterval = 1 * time.Minute
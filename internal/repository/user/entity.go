@@ -14,12 +14,32 @@ type (
 		Birthdate    time.Time // Birthdate of the user.
 		Gender       string    // Gender of the user.
 		Interests    string    // Interests of the user.
+		Role         string    // Role of the user.
+
+		EmailVisibility     string // Who can see the email address of the user.
+		BirthdateVisibility string // Who can see the birthdate of the user.
+		InterestsVisibility string // Who can see the interests of the user.
+	}
+
+	// UpdateFields represents the set of user profile fields to update.
+	UpdateFields struct {
+		CityID    bool // Whether to update the city ID.
+		FirstName bool // Whether to update the first name.
+		LastName  bool // Whether to update the last name.
+		Birthdate bool // Whether to update the birthdate.
+		Gender    bool // Whether to update the gender.
+		Interests bool // Whether to update the interests.
+
+		EmailVisibility     bool // Whether to update who can see the email address.
+		BirthdateVisibility bool // Whether to update who can see the birthdate.
+		InterestsVisibility bool // Whether to update who can see the interests.
 	}
 
-	// LoginData represents the ID and password hash of a user for authentication.
+	// LoginData represents the ID, password hash and role of a user for authentication.
 	LoginData struct {
 		ID           int64  // Unique identifier of the user.
 		PasswordHash string // Hashed password of the user.
+		Role         string // Role of the user.
 	}
 
 	// SearchByNamePrefixesRequest represents a request to search for users by name prefixes.
@@ -35,4 +55,50 @@ type (
 		Items   []*User // List of matching user entities.
 		HasNext bool    // Whether there are more results available.
 	}
+
+	// SearchRequest represents a request to search for users by a free-form query and filters.
+	// Empty query and filters match all users.
+	// Birthdate and interests filters match only users who show them to everyone.
+	SearchRequest struct {
+		Query            string        // Query matched against names (typo-tolerant) and interests.
+		CityIDs          []int16       // IDs of the cities users live in.
+		Gender           string        // Gender of users.
+		MinBirthdate     *time.Time    // Earliest birthdate of users.
+		MaxBirthdate     *time.Time    // Latest birthdate of users.
+		InterestKeywords []string      // Keywords all of which must be present in interests of users.
+		Limit            uint64        // Maximum number of results to return.
+		Cursor           *SearchCursor // Position to continue after, nil for the first page.
+	}
+
+	// SearchCursor represents a position in search results sorted by relevance.
+	SearchCursor struct {
+		Relevance float32 // Relevance of the last returned user.
+		ID        int64   // ID of the last returned user.
+	}
+
+	// SearchResponse represents the response to a search request.
+	SearchResponse struct {
+		Items      []*User       // List of matching user entities sorted by relevance.
+		HasNext    bool          // Whether there are more results available.
+		NextCursor *SearchCursor // Position of the last returned user, nil if there are no more results.
+	}
+
+	// SearchFacets represents the numbers of users matching a search request
+	// per value of a field. Each facet ignores the filter by its own field.
+	SearchFacets struct {
+		Cities  []*CityFacet   // Numbers of users per city, most populated first.
+		Genders []*GenderFacet // Numbers of users per gender, most populated first.
+	}
+
+	// CityFacet represents the number of matching users living in a city.
+	CityFacet struct {
+		CityID int16 // ID of the city.
+		Count  int64 // Number of matching users.
+	}
+
+	// GenderFacet represents the number of matching users of a gender.
+	GenderFacet struct {
+		Gender string // Gender of users.
+		Count  int64  // Number of matching users.
+	}
 )
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	pgx "github.com/jackc/pgx/v5"
@@ -39,9 +40,37 @@ type (
 		// GetByEmail returns a user with the given email address.
 		GetByEmail(ctx context.Context, email string) (*User, error)
 
-		// GetLoginDataByEmail returns login data (email and password hash) for the user with the given email address.
+		// GetLoginDataByEmail returns login data (ID, password hash and role) for the user with the given email address.
 		GetLoginDataByEmail(ctx context.Context, email string) (*LoginData, error)
 
+		// GetLoginDataByID returns login data (ID, password hash and role) for the user with the given ID.
+		GetLoginDataByID(ctx context.Context, id int64) (*LoginData, error)
+
+		// Update updates the given fields of the user with the ID of u.
+		// Returns the updated user or nil if the user doesn't exist.
+		Update(ctx context.Context, u *User, fields *UpdateFields) (*User, error)
+
+		// UpdatePasswordHash replaces the password hash of the user with the given ID.
+		// Returns false if the user doesn't exist.
+		UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) (bool, error)
+
+		// Delete marks the user with the given ID as deleted, so that it's hidden from all other methods.
+		// Returns false if the user doesn't exist or is already deleted.
+		Delete(ctx context.Context, id int64) (bool, error)
+
+		// PurgeDeleted permanently removes users marked as deleted before the given time.
+		// Returns the number of removed users.
+		PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
+
+		// Search returns a list of users matching the query sorted by relevance.
+		// Names are matched case-insensitively with typos tolerated, interests are matched by words
+		// only if they are visible to everyone.
+		Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error)
+
+		// GetSearchFacets returns the numbers of users matching the search request per city and gender.
+		// The limit and the cursor of the request are ignored.
+		GetSearchFacets(ctx context.Context, req *SearchRequest) (*SearchFacets, error)
+
 		// SearchByNamePrefixes returns a list of users whose first and last names start with the given prefixes.
 		// Returns the number of total results and a slice of users.
 		SearchByNamePrefixes(ctx context.Context, req *SearchByNamePrefixesRequest) (*SearchByNamePrefixesResponse, error)
@@ -63,6 +92,19 @@ const (
 	columnBirthdate    = "birthdate"
 	columnGender       = "gender"
 	columnInterests    = "interests"
+	columnRole         = "role"
+	columnDeletedAt    = "deleted_at"
+
+	columnEmailVisibility     = "email_visibility"
+	columnBirthdateVisibility = "birthdate_visibility"
+	columnInterestsVisibility = "interests_visibility"
+	visibilityPublic          = "PUBLIC"
+
+	columnSearchVector = "search_vector"
+	columnRelevance    = "relevance"
+	columnCount        = "count"
+	searchSubqueryName = "found"
+	maxCityFacetsCount = 50
 )
 
 var insertRows = []string{columnEmail,
@@ -74,6 +116,37 @@ var insertRows = []string{columnEmail,
 	columnGender,
 	columnInterests}
 
+var defaultUserFields = []string{columnID,
+	columnEmail,
+	columnPasswordHash,
+	columnCityID,
+	columnFirstName,
+	columnLastName,
+	columnBirthdate,
+	columnGender,
+	columnInterests,
+	columnRole,
+	columnEmailVisibility,
+	columnBirthdateVisibility,
+	columnInterestsVisibility}
+
+// notDeleted filters out users marked as deleted.
+var notDeleted = sq.Eq{columnDeletedAt: nil}
+
+var (
+	// fullNameExpr is indexed by the trigram index used by fuzzy search.
+	fullNameExpr = fmt.Sprintf("(%s || ' ' || %s)", columnFirstName, columnLastName)
+	// searchQueryExpr turns the search query into a full-text query without any special syntax.
+	searchQueryExpr = "plainto_tsquery('simple', ?)"
+	// searchMatchExpr matches users with a name similar to a word of the query or with the words of the query.
+	searchMatchExpr = fmt.Sprintf("(? <%% %s OR %s @@ %s)", fullNameExpr, columnSearchVector, searchQueryExpr)
+	// searchRelevanceExpr is the best of the name similarity and the full-text rank.
+	searchRelevanceExpr = fmt.Sprintf("greatest(word_similarity(?, %s), ts_rank(%s, %s))",
+		fullNameExpr, columnSearchVector, searchQueryExpr)
+	// emptySearchRelevanceExpr is the relevance of all users if the search query is empty.
+	emptySearchRelevanceExpr = "0::real"
+)
+
 // NewRepository creates a new Repository instance with the given database cluster.
 func NewRepository(cluster *db.Cluster) Repository {
 	return &repository{
@@ -85,6 +158,7 @@ func (r *repository) CheckIfExistByEmails(ctx context.Context, emails []string)
 	queryBuilder := sq.Select(columnEmail).
 		From(tableName).
 		Where(sq.Eq{columnEmail: emails}).
+		Where(notDeleted).
 		Limit(uint64(len(emails))).
 		PlaceholderFormat(sq.Dollar)
 
@@ -93,7 +167,7 @@ func (r *repository) CheckIfExistByEmails(ctx context.Context, emails []string)
 		return nil, fmt.Errorf("failed to generate query: %w", err)
 	}
 
-	rows, err := r.cluster.ReadRR().Query(ctx, query, args...)
+	rows, err := r.cluster.ReadRR(ctx).Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run query: %w", err)
 	}
@@ -118,6 +192,7 @@ func (r *repository) CheckIfExistsByEmail(ctx context.Context, email string) (bo
 	sql, args, err := sq.Select(columnID).
 		From(tableName).
 		Where(sq.Eq{columnEmail: email}).
+		Where(notDeleted).
 		Limit(1).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
@@ -127,7 +202,7 @@ func (r *repository) CheckIfExistsByEmail(ctx context.Context, email string) (bo
 
 	var id int64
 
-	err = r.cluster.ReadRR().QueryRow(ctx, sql, args...).Scan(&id)
+	err = r.cluster.ReadRR(ctx).QueryRow(ctx, sql, args...).Scan(&id)
 	if err == nil {
 		return id != 0, nil
 	}
@@ -152,11 +227,13 @@ func (r *repository) Create(ctx context.Context, u *User) (int64, error) {
 
 	var id int64
 
-	err = r.cluster.ReadRR().QueryRow(ctx, sql, args...).Scan(&id)
+	err = r.cluster.Write().QueryRow(ctx, sql, args...).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("failed to read query results: %w", err)
 	}
 
+	r.cluster.TrackWrite(ctx)
+
 	return id, nil
 }
 
@@ -165,15 +242,6 @@ func (r *repository) CreateBatch(ctx context.Context, users []*User) (int64, err
 		return 0, nil
 	}
 
-	writeDB := r.cluster.Write()
-
-	conn, err := writeDB.Acquire(ctx)
-	if err != nil {
-		return 0, fmt.Errorf("failed to acquire connection: %w", err)
-	}
-
-	defer conn.Release()
-
 	rowSrc := pgx.CopyFromSlice(len(users),
 		func(i int) ([]interface{}, error) {
 			user := users[i]
@@ -188,7 +256,7 @@ func (r *repository) CreateBatch(ctx context.Context, users []*User) (int64, err
 				user.Interests}, nil
 		})
 
-	copyCount, err := writeDB.CopyFrom(ctx,
+	copyCount, err := r.cluster.Write().CopyFrom(ctx,
 		pgx.Identifier{tableName},
 		insertRows,
 		rowSrc)
@@ -196,6 +264,8 @@ func (r *repository) CreateBatch(ctx context.Context, users []*User) (int64, err
 		return 0, fmt.Errorf("failed to execute query: %w", err)
 	}
 
+	r.cluster.TrackWrite(ctx)
+
 	return copyCount, nil
 }
 
@@ -222,30 +292,358 @@ func (r *repository) GetByEmail(ctx context.Context, email string) (*User, error
 }
 
 func (r *repository) GetLoginDataByEmail(ctx context.Context, email string) (*LoginData, error) {
-	sql, args, err := sq.Select(columnID,
-		columnPasswordHash).
-		From(tableName).
-		Where(sq.Eq{columnEmail: email}).
-		Limit(1).
+	return r.getLoginData(ctx, sq.Eq{columnEmail: email})
+}
+
+func (r *repository) GetLoginDataByID(ctx context.Context, id int64) (*LoginData, error) {
+	return r.getLoginData(ctx, sq.Eq{columnID: id})
+}
+
+func (r *repository) Update(ctx context.Context, u *User, fields *UpdateFields) (*User, error) {
+	updateBuilder := sq.Update(tableName).
+		Where(sq.Eq{columnID: u.ID}).
+		Where(notDeleted).
+		Suffix(fmt.Sprintf("RETURNING %s", strings.Join(defaultUserFields, ", "))).
+		PlaceholderFormat(sq.Dollar)
+
+	if fields.CityID {
+		updateBuilder = updateBuilder.Set(columnCityID, u.CityID)
+	}
+
+	if fields.FirstName {
+		updateBuilder = updateBuilder.Set(columnFirstName, u.FirstName)
+	}
+
+	if fields.LastName {
+		updateBuilder = updateBuilder.Set(columnLastName, u.LastName)
+	}
+
+	if fields.Birthdate {
+		updateBuilder = updateBuilder.Set(columnBirthdate, u.Birthdate)
+	}
+
+	if fields.Gender {
+		updateBuilder = updateBuilder.Set(columnGender, u.Gender)
+	}
+
+	if fields.Interests {
+		updateBuilder = updateBuilder.Set(columnInterests, u.Interests)
+	}
+
+	if fields.EmailVisibility {
+		updateBuilder = updateBuilder.Set(columnEmailVisibility, u.EmailVisibility)
+	}
+
+	if fields.BirthdateVisibility {
+		updateBuilder = updateBuilder.Set(columnBirthdateVisibility, u.BirthdateVisibility)
+	}
+
+	if fields.InterestsVisibility {
+		updateBuilder = updateBuilder.Set(columnInterestsVisibility, u.InterestsVisibility)
+	}
+
+	sql, args, err := updateBuilder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query: %w", err)
+	}
+
+	updated, err := scanUserRow(r.cluster.Write().QueryRow(ctx, sql, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	if updated != nil {
+		r.cluster.TrackWrite(ctx)
+	}
+
+	return updated, nil
+}
+
+func (r *repository) UpdatePasswordHash(ctx context.Context, id int64, passwordHash string) (bool, error) {
+	sql, args, err := sq.Update(tableName).
+		Set(columnPasswordHash, passwordHash).
+		Where(sq.Eq{columnID: id}).
+		Where(notDeleted).
 		PlaceholderFormat(sq.Dollar).
 		ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate query: %w", err)
+		return false, fmt.Errorf("failed to generate query: %w", err)
 	}
 
-	var u LoginData
+	commandTag, err := r.cluster.Write().Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
 
-	err = r.cluster.ReadRR().QueryRow(ctx, sql, args...).Scan(&u.ID,
-		&u.PasswordHash)
-	if err == nil {
-		return &u, nil
+	if commandTag.RowsAffected() == 0 {
+		return false, nil
 	}
 
-	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil
+	r.cluster.TrackWrite(ctx)
+
+	return true, nil
+}
+
+func (r *repository) Delete(ctx context.Context, id int64) (bool, error) {
+	sql, args, err := sq.Update(tableName).
+		Set(columnDeletedAt, sq.Expr("now()")).
+		Where(sq.Eq{columnID: id}).
+		Where(notDeleted).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to generate query: %w", err)
 	}
 
-	return nil, fmt.Errorf("failed to read query results: %w", err)
+	commandTag, err := r.cluster.Write().Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	r.cluster.TrackWrite(ctx)
+
+	return true, nil
+}
+
+func (r *repository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	sql, args, err := sq.Delete(tableName).
+		Where(sq.Lt{columnDeletedAt: deletedBefore}).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate query: %w", err)
+	}
+
+	commandTag, err := r.cluster.Write().Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+func (r *repository) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+	relevance := sq.Expr(emptySearchRelevanceExpr)
+	if req.Query != "" {
+		relevance = sq.Expr(searchRelevanceExpr, req.Query, req.Query)
+	}
+
+	foundQB := r.filterSearch(sq.Select(defaultUserFields...).
+		Column(sq.Alias(relevance, columnRelevance)).
+		From(tableName), req, "")
+
+	fields := make([]string, 0, len(defaultUserFields)+1)
+	fields = append(fields, defaultUserFields...)
+	fields = append(fields, columnRelevance)
+
+	selectQB := sq.Select(fields...).
+		FromSelect(foundQB, searchSubqueryName).
+		OrderBy(fmt.Sprintf("%s DESC", columnRelevance), fmt.Sprintf("%s ASC", columnID)).
+		Limit(req.Limit + 1).
+		PlaceholderFormat(sq.Dollar)
+	if c := req.Cursor; c != nil {
+		selectQB = selectQB.Where(sq.Or{
+			sq.Lt{columnRelevance: c.Relevance},
+			sq.And{
+				sq.Eq{columnRelevance: c.Relevance},
+				sq.Gt{columnID: c.ID},
+			},
+		})
+	}
+
+	selectQuery, selectArgs, err := selectQB.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build select query: %w", err)
+	}
+
+	rows, err := r.cluster.ReadRR(ctx).Query(ctx, selectQuery, selectArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run select query: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		users      []*User
+		nextCursor *SearchCursor
+		hasNext    bool
+	)
+
+	for rows.Next() {
+		if uint64(len(users)) >= req.Limit {
+			hasNext = true
+			break
+		}
+
+		var (
+			user      User
+			relevance float32
+		)
+
+		err = rows.Scan(&user.ID,
+			&user.Email,
+			&user.PasswordHash,
+			&user.CityID,
+			&user.FirstName,
+			&user.LastName,
+			&user.Birthdate,
+			&user.Gender,
+			&user.Interests,
+			&user.Role,
+			&user.EmailVisibility,
+			&user.BirthdateVisibility,
+			&user.InterestsVisibility,
+			&relevance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read select query results: %w", err)
+		}
+
+		users = append(users, &user)
+		nextCursor = &SearchCursor{
+			Relevance: relevance,
+			ID:        user.ID,
+		}
+	}
+
+	if !hasNext {
+		nextCursor = nil
+	}
+
+	return &SearchResponse{
+		Items:      users,
+		HasNext:    hasNext,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+func (r *repository) GetSearchFacets(ctx context.Context, req *SearchRequest) (*SearchFacets, error) {
+	countColumn := fmt.Sprintf("count(*) AS %s", columnCount)
+	sortByCount := fmt.Sprintf("%s DESC", columnCount)
+
+	citiesQuery, citiesArgs, err := r.filterSearch(sq.Select(columnCityID, countColumn).
+		From(tableName), req, columnCityID).
+		GroupBy(columnCityID).
+		OrderBy(sortByCount, fmt.Sprintf("%s ASC", columnCityID)).
+		Limit(maxCityFacetsCount).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build cities query: %w", err)
+	}
+
+	gendersQuery, gendersArgs, err := r.filterSearch(sq.Select(columnGender, countColumn).
+		From(tableName), req, columnGender).
+		GroupBy(columnGender).
+		OrderBy(sortByCount, fmt.Sprintf("%s ASC", columnGender)).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build genders query: %w", err)
+	}
+
+	cities, err := r.getCityFacets(ctx, citiesQuery, citiesArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	genders, err := r.getGenderFacets(ctx, gendersQuery, gendersArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SearchFacets{
+		Cities:  cities,
+		Genders: genders,
+	}, nil
+}
+
+func (r *repository) getCityFacets(ctx context.Context, query string, args ...any) ([]*CityFacet, error) {
+	rows, err := r.cluster.ReadRR(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run cities query: %w", err)
+	}
+	defer rows.Close()
+
+	var facets []*CityFacet
+
+	for rows.Next() {
+		var f CityFacet
+
+		if err = rows.Scan(&f.CityID, &f.Count); err != nil {
+			return nil, fmt.Errorf("failed to read cities query results: %w", err)
+		}
+
+		facets = append(facets, &f)
+	}
+
+	return facets, nil
+}
+
+func (r *repository) getGenderFacets(ctx context.Context, query string, args ...any) ([]*GenderFacet, error) {
+	rows, err := r.cluster.ReadRR(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run genders query: %w", err)
+	}
+	defer rows.Close()
+
+	var facets []*GenderFacet
+
+	for rows.Next() {
+		var f GenderFacet
+
+		if err = rows.Scan(&f.Gender, &f.Count); err != nil {
+			return nil, fmt.Errorf("failed to read genders query results: %w", err)
+		}
+
+		facets = append(facets, &f)
+	}
+
+	return facets, nil
+}
+
+// filterSearch adds the conditions of the search request to the query,
+// except the filter by the given column, which is used to count facets.
+func (r *repository) filterSearch(qb sq.SelectBuilder, req *SearchRequest, exceptColumn string) sq.SelectBuilder {
+	qb = qb.Where(notDeleted)
+
+	if req.Query != "" {
+		qb = qb.Where(sq.Expr(searchMatchExpr, req.Query, req.Query))
+	}
+
+	if len(req.CityIDs) > 0 && exceptColumn != columnCityID {
+		qb = qb.Where(sq.Eq{columnCityID: req.CityIDs})
+	}
+
+	if req.Gender != "" && exceptColumn != columnGender {
+		qb = qb.Where(sq.Eq{columnGender: req.Gender})
+	}
+
+	// Birthdates and interests are filtered only if they are visible to everyone,
+	// otherwise the hidden values could be found out by narrowing the filters.
+	if req.MinBirthdate != nil || req.MaxBirthdate != nil {
+		qb = qb.Where(sq.Eq{columnBirthdateVisibility: visibilityPublic})
+	}
+
+	if req.MinBirthdate != nil {
+		qb = qb.Where(sq.GtOrEq{columnBirthdate: *req.MinBirthdate})
+	}
+
+	if req.MaxBirthdate != nil {
+		qb = qb.Where(sq.LtOrEq{columnBirthdate: *req.MaxBirthdate})
+	}
+
+	if len(req.InterestKeywords) > 0 {
+		qb = qb.Where(sq.Eq{columnInterestsVisibility: visibilityPublic})
+	}
+
+	for _, v := range req.InterestKeywords {
+		qb = qb.Where(sq.ILike{columnInterests: strings.Join([]string{"%", common.EscapeLike(v), "%"}, "")})
+	}
+
+	return qb
 }
 
 func (r *repository) SearchByNamePrefixes(ctx context.Context,
@@ -270,7 +668,7 @@ func (r *repository) SearchByNamePrefixes(ctx context.Context,
 		return nil, fmt.Errorf("failed to build select query: %w", err)
 	}
 
-	rows, err := r.cluster.ReadRR().Query(ctx, selectQuery, selectArgs...)
+	rows, err := r.cluster.ReadRR(ctx).Query(ctx, selectQuery, selectArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run select query: %w", err)
 	}
@@ -297,7 +695,11 @@ func (r *repository) SearchByNamePrefixes(ctx context.Context,
 			&user.LastName,
 			&user.Birthdate,
 			&user.Gender,
-			&user.Interests)
+			&user.Interests,
+			&user.Role,
+			&user.EmailVisibility,
+			&user.BirthdateVisibility,
+			&user.InterestsVisibility)
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to read select query results: %w", err)
@@ -313,24 +715,51 @@ func (r *repository) SearchByNamePrefixes(ctx context.Context,
 }
 
 func (r *repository) selectDefaultUserFields(limit uint64) sq.SelectBuilder {
-	return sq.Select(columnID,
-		columnEmail,
-		columnPasswordHash,
-		columnCityID,
-		columnFirstName,
-		columnLastName,
-		columnBirthdate,
-		columnGender,
-		columnInterests).
+	return sq.Select(defaultUserFields...).
 		From(tableName).
+		Where(notDeleted).
 		Limit(limit).
 		PlaceholderFormat(sq.Dollar)
 }
 
+func (r *repository) getLoginData(ctx context.Context, filter sq.Eq) (*LoginData, error) {
+	sql, args, err := sq.Select(columnID,
+		columnPasswordHash,
+		columnRole).
+		From(tableName).
+		Where(filter).
+		Where(notDeleted).
+		Limit(1).
+		PlaceholderFormat(sq.Dollar).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query: %w", err)
+	}
+
+	var u LoginData
+
+	err = r.cluster.ReadRR(ctx).QueryRow(ctx, sql, args...).Scan(&u.ID,
+		&u.PasswordHash,
+		&u.Role)
+	if err == nil {
+		return &u, nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("failed to read query results: %w", err)
+}
+
 func (r *repository) scanUser(ctx context.Context, sql string, args ...any) (*User, error) {
+	return scanUserRow(r.cluster.ReadRR(ctx).QueryRow(ctx, sql, args...))
+}
+
+func scanUserRow(row pgx.Row) (*User, error) {
 	var u User
 
-	err := r.cluster.ReadRR().QueryRow(ctx, sql, args...).Scan(&u.ID,
+	err := row.Scan(&u.ID,
 		&u.Email,
 		&u.PasswordHash,
 		&u.CityID,
@@ -338,7 +767,11 @@ func (r *repository) scanUser(ctx context.Context, sql string, args ...any) (*Us
 		&u.LastName,
 		&u.Birthdate,
 		&u.Gender,
-		&u.Interests)
+		&u.Interests,
+		&u.Role,
+		&u.EmailVisibility,
+		&u.BirthdateVisibility,
+		&u.InterestsVisibility)
 	if err == nil {
 		return &u, nil
 	}
@@ -1,9 +1,12 @@
 package common
 
+import "time"
+
 // Error represents an error with an associated ErrorStatus.
 type Error struct {
-	Type ErrorStatus // Type is the type of error that occurred.
-	Err  error       // Err is the underlying error that caused the error.
+	Type       ErrorStatus   // Type is the type of error that occurred.
+	Err        error         // Err is the underlying error that caused the error.
+	RetryAfter time.Duration // RetryAfter is the time to wait before retrying the request (0 if it's not known).
 }
 
 // NewError creates a new Error object with the given ErrorStatus and error.
@@ -14,6 +17,16 @@ func NewError(status ErrorStatus, err error) *Error {
 	}
 }
 
+// NewTooManyRequestsError creates a new Error object with ErrStatusTooManyRequests
+// telling to retry the request after the given duration.
+func NewTooManyRequestsError(retryAfter time.Duration, err error) *Error {
+	return &Error{
+		Type:       ErrStatusTooManyRequests,
+		Err:        err,
+		RetryAfter: retryAfter,
+	}
+}
+
 // Error returns a string representation of the error message.
 func (e *Error) Error() string {
 	return e.Err.Error()
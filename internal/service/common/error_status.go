@@ -7,13 +7,14 @@ type ErrorStatus uint8
 
 // Constants representing different error status codes.
 const (
-	ErrStatusUnknown       ErrorStatus = iota // Unknown error status
-	ErrStatusBadRequest                       // Bad request error status
-	ErrStatusUnauthorized                     // Unauthorized error status
-	ErrStatusForbidden                        // Forbidden error status
-	ErrStatusNotFound                         // Not found error status
-	ErrStatusConflict                         // Conflict error status
-	ErrStatusInternalError                    // Internal error status
+	ErrStatusUnknown         ErrorStatus = iota // Unknown error status
+	ErrStatusBadRequest                         // Bad request error status
+	ErrStatusUnauthorized                       // Unauthorized error status
+	ErrStatusForbidden                          // Forbidden error status
+	ErrStatusNotFound                           // Not found error status
+	ErrStatusConflict                           // Conflict error status
+	ErrStatusInternalError                      // Internal error status
+	ErrStatusTooManyRequests                    // Too many requests error status
 
 	unknownErrorCode = "UNKNOWN_ERROR"
 )
@@ -31,6 +32,8 @@ func (es ErrorStatus) HTTPStatus() int {
 		return http.StatusNotFound
 	case ErrStatusConflict:
 		return http.StatusConflict
+	case ErrStatusTooManyRequests:
+		return http.StatusTooManyRequests
 	case ErrStatusUnknown, ErrStatusInternalError:
 		return http.StatusInternalServerError
 	default:
@@ -55,6 +58,8 @@ func (es ErrorStatus) String() string {
 		return "CONFLICT"
 	case ErrStatusInternalError:
 		return "INTERNAL_ERROR"
+	case ErrStatusTooManyRequests:
+		return "TOO_MANY_REQUESTS"
 	default:
 		return unknownErrorCode
 	}
@@ -0,0 +1,71 @@
+package counter
+
+import (
+	"fmt"
+
+	repo "github.com/oshokin/hive-backend/internal/repository/counter"
+)
+
+type (
+	// DialogCounter represents the number of unread messages in the dialog with a partner.
+	DialogCounter struct {
+		PartnerID   int64 // ID of the other participant of the dialog
+		UnreadCount int64 // number of unread messages
+	}
+
+	// Counters represents all unread message counters of a user.
+	Counters struct {
+		Total   int64            // total number of unread messages
+		Dialogs []*DialogCounter // counters of dialogs with unread messages
+	}
+
+	// MarkReadRequest represents a request to mark messages in a dialog as read.
+	MarkReadRequest struct {
+		UserID    int64 // ID of the user who read the messages
+		PartnerID int64 // ID of the other participant of the dialog
+		MessageID int64 // ID of the last read message (0 means all messages)
+	}
+)
+
+// reconciliationBatchSize defines the number of counters checked in a single query.
+const reconciliationBatchSize = 1000
+
+func (s *service) getServiceModel(source []*repo.Counter) *Counters {
+	result := &Counters{
+		Dialogs: make([]*DialogCounter, 0, len(source)),
+	}
+
+	for _, v := range source {
+		if v == nil {
+			continue
+		}
+
+		result.Total += v.UnreadCount
+		result.Dialogs = append(result.Dialogs, &DialogCounter{
+			PartnerID:   v.PartnerID,
+			UnreadCount: v.UnreadCount,
+		})
+	}
+
+	return result
+}
+
+func (r *MarkReadRequest) validate() error {
+	if r == nil {
+		return nil
+	}
+
+	if r.UserID <= 0 {
+		return fmt.Errorf("user ID must be greater than 0")
+	}
+
+	if r.PartnerID <= 0 {
+		return fmt.Errorf("partner ID must be greater than 0")
+	}
+
+	if r.MessageID < 0 {
+		return fmt.Errorf("message ID must be greater than or equal to 0")
+	}
+
+	return nil
+}
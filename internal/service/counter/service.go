@@ -0,0 +1,244 @@
+// Package counter provides a service to count unread messages in dialogs.
+package counter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/oshokin/hive-backend/internal/common"
+	"github.com/oshokin/hive-backend/internal/logger"
+	counter_repo "github.com/oshokin/hive-backend/internal/repository/counter"
+	dialog_repo "github.com/oshokin/hive-backend/internal/repository/dialog"
+	common_service "github.com/oshokin/hive-backend/internal/service/common"
+)
+
+type (
+	// Service provides methods for managing unread message counters.
+	Service interface {
+		// Start starts the reconciliation of counters with the messages.
+		Start(ctx context.Context)
+		// Stop stops the reconciliation of counters with the messages.
+		Stop(ctx context.Context)
+		// Get gets all unread message counters of the user.
+		Get(ctx context.Context, userID int64) (*Counters, error)
+		// Increment increases the number of unread messages the user received from the partner.
+		Increment(ctx context.Context, userID, partnerID int64) error
+		// MarkRead marks messages of the dialog as read and decreases the number of unread messages.
+		MarkRead(ctx context.Context, req *MarkReadRequest) error
+	}
+
+	service struct {
+		counterRepository counter_repo.Repository
+		dialogRepository  dialog_repo.Repository
+		cancel            context.CancelFunc
+		mu                sync.Mutex
+	}
+)
+
+const (
+	reconciliationTimeout = 1 * time.Minute
+	maxMarkReadAttempts   = 3
+)
+
+var errInvalidUserID = common_service.NewError(common_service.ErrStatusBadRequest,
+	errors.New("user ID must be greater than 0"))
+
+// NewService returns a new instance of the counter service.
+func NewService(c counter_repo.Repository, d dialog_repo.Repository) Service {
+	return &service{
+		counterRepository: c,
+		dialogRepository:  d,
+	}
+}
+
+func (s *service) Start(ctx context.Context) {
+	logger.Info(ctx, "starting counter service")
+
+	s.mu.Lock()
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.mu.Unlock()
+
+	go s.start(ctx)
+
+	logger.Info(ctx, "counter service is running")
+}
+
+func (s *service) Stop(ctx context.Context) {
+	logger.Info(ctx, "shutting down counter service")
+	s.mu.Lock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	s.mu.Unlock()
+	logger.Info(ctx, "counter service stopped")
+}
+
+func (s *service) Get(ctx context.Context, userID int64) (*Counters, error) {
+	if userID <= 0 {
+		return nil, errInvalidUserID
+	}
+
+	res, err := s.counterRepository.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to read counters: %w", err))
+	}
+
+	return s.getServiceModel(res), nil
+}
+
+func (s *service) Increment(ctx context.Context, userID, partnerID int64) error {
+	if err := s.counterRepository.Increment(ctx, userID, partnerID); err != nil {
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to increment counter: %w", err))
+	}
+
+	return nil
+}
+
+func (s *service) MarkRead(ctx context.Context, r *MarkReadRequest) error {
+	if err := r.validate(); err != nil {
+		return common_service.NewError(common_service.ErrStatusBadRequest, err)
+	}
+
+	dialogID := dialog_repo.GetDialogID(r.UserID, r.PartnerID)
+
+	lastReadMessageID, err := s.dialogRepository.GetLastMessageID(ctx, dialogID)
+	if err != nil {
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to get last message: %w", err))
+	}
+
+	// The message ID is clamped to the last message, otherwise future messages would never be counted.
+	if r.MessageID != 0 && r.MessageID < lastReadMessageID {
+		lastReadMessageID = r.MessageID
+	}
+
+	for i := 0; i < maxMarkReadAttempts; i++ {
+		isMarked, err := s.markRead(ctx, r, dialogID, lastReadMessageID)
+		if err != nil {
+			return common_service.NewError(common_service.ErrStatusInternalError,
+				fmt.Errorf("failed to mark messages as read: %w", err))
+		}
+
+		if isMarked {
+			return nil
+		}
+	}
+
+	// The counter keeps changing concurrently, reconciliation will fix it later.
+	logger.WarnKV(ctx, "failed to mark messages as read, counter is changing concurrently",
+		common.UserIDTag, r.UserID,
+		common.PartnerIDTag, r.PartnerID)
+
+	return nil
+}
+
+// markRead returns false if the counter was changed concurrently and the operation must be retried.
+func (s *service) markRead(ctx context.Context, r *MarkReadRequest, dialogID string, lastReadMessageID int64) (bool, error) {
+	c, err := s.counterRepository.GetByUserAndPartner(ctx, r.UserID, r.PartnerID)
+	if err != nil {
+		return false, err
+	}
+
+	// The user has never received messages from the partner or has already read them.
+	if c == nil || c.LastReadMessageID >= lastReadMessageID {
+		return true, nil
+	}
+
+	readCount, err := s.dialogRepository.CountReceived(ctx, dialogID, r.UserID, c.LastReadMessageID, lastReadMessageID)
+	if err != nil {
+		return false, err
+	}
+
+	return s.counterRepository.MarkRead(ctx, c, lastReadMessageID, readCount)
+}
+
+func (s *service) start(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Infof(ctx, "counter reconciliation context was cancelled")
+			return
+		default:
+			if err := s.reconcile(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				logger.ErrorKV(ctx, "failed to reconcile counters", common.ErrorTag, err)
+			}
+
+			time.Sleep(reconciliationTimeout)
+		}
+	}
+}
+
+// reconcile recounts unread messages of every counter and fixes the ones that drifted.
+// Counters that were never created, e.g. because the first message of a dialog failed to increment them,
+// are created empty beforehand so the recount fixes them too.
+func (s *service) reconcile(ctx context.Context) error {
+	createdCount, err := s.counterRepository.CreateMissing(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create missing counters: %w", err)
+	}
+
+	if createdCount > 0 {
+		logger.InfoKV(ctx, "created missing unread messages counters", common.CreatedCountTag, createdCount)
+	}
+
+	req := &counter_repo.GetListRequest{
+		Limit: reconciliationBatchSize,
+	}
+
+	for {
+		res, err := s.counterRepository.GetList(ctx, req)
+		if err != nil {
+			return fmt.Errorf("failed to get counters: %w", err)
+		}
+
+		for _, c := range res.Items {
+			if err = s.reconcileCounter(ctx, c); err != nil {
+				return err
+			}
+		}
+
+		if !res.HasNext || len(res.Items) == 0 {
+			return nil
+		}
+
+		last := res.Items[len(res.Items)-1]
+		req.CursorUserID = last.UserID
+		req.CursorPartnerID = last.PartnerID
+	}
+}
+
+func (s *service) reconcileCounter(ctx context.Context, c *counter_repo.Counter) error {
+	dialogID := dialog_repo.GetDialogID(c.UserID, c.PartnerID)
+
+	actualCount, err := s.dialogRepository.CountReceived(ctx, dialogID, c.UserID, c.LastReadMessageID, 0)
+	if err != nil {
+		return fmt.Errorf("failed to count unread messages: %w", err)
+	}
+
+	if actualCount == c.UnreadCount {
+		return nil
+	}
+
+	// If the counter was changed concurrently, it will be checked again on the next run.
+	isFixed, err := s.counterRepository.SetUnreadCount(ctx, c, actualCount)
+	if err != nil {
+		return fmt.Errorf("failed to fix counter: %w", err)
+	}
+
+	if isFixed {
+		logger.InfoKV(ctx, "fixed unread messages counter",
+			common.UserIDTag, c.UserID,
+			common.PartnerIDTag, c.PartnerID,
+			common.CurrentCountTag, c.UnreadCount,
+			common.ActualCountTag, actualCount)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package dialog
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	repo "github.com/oshokin/hive-backend/internal/repository/dialog"
+)
+
+type (
+	// Message represents a private message sent from one user to another.
+	Message struct {
+		ID         int64     // unique identifier of the message
+		SenderID   int64     // ID of the user who sent the message
+		ReceiverID int64     // ID of the user who received the message
+		Text       string    // text of the message
+		CreatedAt  time.Time // the time when the message was sent
+	}
+
+	// GetListRequest represents a request to get messages of a dialog between two users.
+	GetListRequest struct {
+		UserID    int64  // ID of the user who requests the dialog
+		PartnerID int64  // ID of the other participant of the dialog
+		Limit     uint64 // maximum number of messages to return
+		Cursor    int64  // ID of the last message from the previous page (0 means the newest messages)
+	}
+
+	// GetListResponse represents a response containing messages of a dialog.
+	GetListResponse struct {
+		Items   []*Message // messages ordered from the newest to the oldest
+		HasNext bool       // whether there are more messages to retrieve
+	}
+)
+
+const (
+	// maxMessagesLimit defines the maximum number of messages to be returned in a single request.
+	maxMessagesLimit = 50
+	// maxMessageTextLength defines the maximum number of characters in a message.
+	maxMessageTextLength = 4000
+)
+
+func (s *service) getServiceModel(source *repo.Message) *Message {
+	if source == nil {
+		return nil
+	}
+
+	return &Message{
+		ID:         source.ID,
+		SenderID:   source.SenderID,
+		ReceiverID: source.ReceiverID,
+		Text:       source.Text,
+		CreatedAt:  source.CreatedAt,
+	}
+}
+
+func (s *service) getServiceModels(source []*repo.Message) []*Message {
+	result := make([]*Message, 0, len(source))
+
+	for _, v := range source {
+		sm := s.getServiceModel(v)
+		if sm == nil {
+			continue
+		}
+
+		result = append(result, sm)
+	}
+
+	return result
+}
+
+func (s *service) getRepoModel(source *Message) *repo.Message {
+	if source == nil {
+		return nil
+	}
+
+	return &repo.Message{
+		DialogID:   repo.GetDialogID(source.SenderID, source.ReceiverID),
+		ID:         source.ID,
+		SenderID:   source.SenderID,
+		ReceiverID: source.ReceiverID,
+		Text:       source.Text,
+		CreatedAt:  source.CreatedAt,
+	}
+}
+
+func (m *Message) validate() error {
+	if m == nil {
+		return nil
+	}
+
+	if m.SenderID <= 0 {
+		return fmt.Errorf("sender ID must be greater than 0")
+	}
+
+	if m.ReceiverID <= 0 {
+		return fmt.Errorf("receiver ID must be greater than 0")
+	}
+
+	if m.SenderID == m.ReceiverID {
+		return fmt.Errorf("sender and receiver must be different users")
+	}
+
+	// The text is trimmed before validation, so the length is checked as it's stored.
+	textLength := utf8.RuneCountInString(m.Text)
+	if textLength == 0 {
+		return fmt.Errorf("text is required")
+	}
+
+	if textLength > maxMessageTextLength {
+		return fmt.Errorf("text cannot be longer than %d characters", maxMessageTextLength)
+	}
+
+	return nil
+}
+
+func (r *GetListRequest) validate() error {
+	if r == nil {
+		return nil
+	}
+
+	if r.UserID <= 0 {
+		return fmt.Errorf("user ID must be greater than 0")
+	}
+
+	if r.PartnerID <= 0 {
+		return fmt.Errorf("partner ID must be greater than 0")
+	}
+
+	if r.Limit > maxMessagesLimit {
+		return fmt.Errorf("maximum messages count in one request is %d items", maxMessagesLimit)
+	}
+
+	if r.Cursor < 0 {
+		return fmt.Errorf("cursor must be greater than or equal to 0")
+	}
+
+	return nil
+}
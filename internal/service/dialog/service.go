@@ -0,0 +1,98 @@
+// Package dialog provides a service to exchange private messages between users.
+package dialog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/oshokin/hive-backend/internal/common"
+	"github.com/oshokin/hive-backend/internal/logger"
+	dialog_repo "github.com/oshokin/hive-backend/internal/repository/dialog"
+	common_service "github.com/oshokin/hive-backend/internal/service/common"
+	counter_service "github.com/oshokin/hive-backend/internal/service/counter"
+	user_service "github.com/oshokin/hive-backend/internal/service/user"
+)
+
+type (
+	// Service defines the methods to manage dialogs.
+	Service interface {
+		// Send sends the message and returns its ID.
+		Send(ctx context.Context, m *Message) (int64, error)
+		// GetList gets a paginated list of messages in the dialog between two users.
+		GetList(ctx context.Context, req *GetListRequest) (*GetListResponse, error)
+	}
+
+	service struct {
+		dialogRepository dialog_repo.Repository
+		userService      user_service.Service
+		counterService   counter_service.Service
+	}
+)
+
+// NewService returns a new instance of the dialog service.
+func NewService(r dialog_repo.Repository, u user_service.Service, c counter_service.Service) Service {
+	return &service{
+		dialogRepository: r,
+		userService:      u,
+		counterService:   c,
+	}
+}
+
+func (s *service) Send(ctx context.Context, m *Message) (int64, error) {
+	m.Text = strings.TrimSpace(m.Text)
+	if err := m.validate(); err != nil {
+		return 0, common_service.NewError(common_service.ErrStatusBadRequest, err)
+	}
+
+	// Returns a not found error if there is no such user.
+	if _, err := s.userService.GetByID(ctx, m.ReceiverID); err != nil {
+		return 0, err
+	}
+
+	rm := s.getRepoModel(m)
+
+	messageID, err := s.dialogRepository.Create(ctx, rm)
+	if err != nil {
+		return 0, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to send message: %w", err))
+	}
+
+	m.ID = messageID
+	m.CreatedAt = rm.CreatedAt
+
+	// The message is already sent, a drifted or missing counter will be fixed by reconciliation.
+	if err = s.counterService.Increment(ctx, m.ReceiverID, m.SenderID); err != nil {
+		logger.ErrorKV(ctx, "failed to increment unread messages counter",
+			common.UserIDTag, m.ReceiverID,
+			common.PartnerIDTag, m.SenderID,
+			common.ErrorTag, err)
+	}
+
+	return messageID, nil
+}
+
+func (s *service) GetList(ctx context.Context, r *GetListRequest) (*GetListResponse, error) {
+	if err := r.validate(); err != nil {
+		return nil, common_service.NewError(common_service.ErrStatusBadRequest, err)
+	}
+
+	limit := r.Limit
+	if limit == 0 {
+		limit = maxMessagesLimit
+	}
+
+	res, err := s.dialogRepository.GetList(ctx, &dialog_repo.GetListRequest{
+		DialogID: dialog_repo.GetDialogID(r.UserID, r.PartnerID),
+		Limit:    limit,
+		Cursor:   r.Cursor,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetListResponse{
+		Items:   s.getServiceModels(res.Items),
+		HasNext: res.HasNext,
+	}, nil
+}
@@ -0,0 +1,99 @@
+package feed
+
+import (
+	"fmt"
+	"time"
+
+	post_repo "github.com/oshokin/hive-backend/internal/repository/post"
+)
+
+type (
+	// Post represents a post shown in a user's news feed.
+	Post struct {
+		ID        int64      // unique identifier of the post
+		AuthorID  int64      // ID of the user who wrote the post
+		Text      string     // text of the post
+		CreatedAt time.Time  // the time when the post was created
+		UpdatedAt *time.Time // the time when the post was last updated (nil if it was never updated)
+	}
+
+	// GetRequest represents a request to get a page of the user's news feed.
+	GetRequest struct {
+		UserID int64  // ID of the user whose feed is requested
+		Offset uint64 // number of the newest posts to skip
+		Limit  uint64 // maximum number of posts to return
+	}
+
+	// GetResponse represents a page of the user's news feed.
+	GetResponse struct {
+		Items   []*Post // posts ordered from the newest to the oldest
+		HasNext bool    // whether there are more posts to retrieve
+	}
+
+	eventType uint8
+
+	// event is a post change that must be fanned out to feeds of the author's followers.
+	event struct {
+		eventType eventType
+		post      *Post
+	}
+)
+
+// Types of events processed by fan-out workers.
+const (
+	eventTypePostCreated eventType = iota
+	eventTypePostUpdated
+	eventTypePostDeleted
+)
+
+// maxPostsLimit defines the maximum number of posts to be returned in a single request.
+const maxPostsLimit = 50
+
+func (s *service) getServiceModel(source *post_repo.Post) *Post {
+	if source == nil {
+		return nil
+	}
+
+	return &Post{
+		ID:        source.ID,
+		AuthorID:  source.AuthorID,
+		Text:      source.Text,
+		CreatedAt: source.CreatedAt,
+		UpdatedAt: source.UpdatedAt,
+	}
+}
+
+func (s *service) getServiceModels(source []*post_repo.Post) []*Post {
+	result := make([]*Post, 0, len(source))
+
+	for _, v := range source {
+		sm := s.getServiceModel(v)
+		if sm == nil {
+			continue
+		}
+
+		result = append(result, sm)
+	}
+
+	return result
+}
+
+func (r *GetRequest) validate() error {
+	if r == nil {
+		return nil
+	}
+
+	if r.UserID <= 0 {
+		return fmt.Errorf("user ID must be greater than 0")
+	}
+
+	if r.Limit > maxPostsLimit {
+		return fmt.Errorf("maximum posts count in one request is %d items", maxPostsLimit)
+	}
+
+	if r.Offset >= maxFeedLength {
+		return fmt.Errorf("offset must be less than %d", maxFeedLength)
+	}
+
+	return nil
+}
@@ -0,0 +1,300 @@
+// Package feed provides a service that keeps materialized news feeds of users in memory.
+package feed
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/oshokin/hive-backend/internal/common"
+	"github.com/oshokin/hive-backend/internal/logger"
+	friend_repo "github.com/oshokin/hive-backend/internal/repository/friend"
+	post_repo "github.com/oshokin/hive-backend/internal/repository/post"
+	common_service "github.com/oshokin/hive-backend/internal/service/common"
+	go_cache "github.com/patrickmn/go-cache"
+)
+
+type (
+	// Service provides methods for reading news feeds and keeping them up to date.
+	Service interface {
+		// Start starts the fan-out workers of the feed service.
+		Start(ctx context.Context)
+		// Stop stops the fan-out workers of the feed service.
+		Stop(ctx context.Context)
+		// Get gets a page of the user's news feed.
+		// The feed is rebuilt from the database if it's not cached yet.
+		Get(ctx context.Context, req *GetRequest) (*GetResponse, error)
+		// AddPost adds a new post to the feeds of the author's followers.
+		AddPost(ctx context.Context, p *Post)
+		// UpdatePost updates the post in the feeds of the author's followers.
+		UpdatePost(ctx context.Context, p *Post)
+		// DeletePost deletes the post from the feeds of the author's followers.
+		DeletePost(ctx context.Context, p *Post)
+		// Invalidate drops the cached feed of the user, so it will be rebuilt on the next read.
+		Invalidate(ctx context.Context, userID int64)
+		// InvalidateFollowers drops the cached feeds of the user's followers, so they will be rebuilt on the next read.
+		InvalidateFollowers(ctx context.Context, userID int64)
+	}
+
+	service struct {
+		friendRepository friend_repo.Repository
+		postRepository   post_repo.Repository
+		feeds            *go_cache.Cache
+		versions         [feedVersionsCount]atomic.Uint64
+		events           chan *event
+		ctx              context.Context
+		cancel           context.CancelFunc
+		mu               sync.Mutex
+		wg               sync.WaitGroup
+	}
+)
+
+const (
+	feedExpirationTime   = 1 * time.Hour
+	feedCleanupInterval  = 10 * time.Minute
+	fanOutWorkersCount   = 4
+	fanOutEventsCapacity = 1000
+	// feedVersionsCount is the number of feed version counters, users share them by the remainder of the ID.
+	feedVersionsCount = 1024
+)
+
+// NewService returns a new instance of the feed service.
+func NewService(f friend_repo.Repository, p post_repo.Repository) Service {
+	return &service{
+		friendRepository: f,
+		postRepository:   p,
+		feeds:            go_cache.New(feedExpirationTime, feedCleanupInterval),
+		events:           make(chan *event, fanOutEventsCapacity),
+	}
+}
+
+func (s *service) Start(ctx context.Context) {
+	logger.Info(ctx, "starting feed service")
+
+	s.mu.Lock()
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	s.wg.Add(fanOutWorkersCount)
+
+	for i := 0; i < fanOutWorkersCount; i++ {
+		go func() {
+			defer s.wg.Done()
+			s.runFanOutWorker(ctx)
+		}()
+	}
+
+	logger.Info(ctx, "feed service is running")
+}
+
+func (s *service) Stop(ctx context.Context) {
+	logger.Info(ctx, "shutting down feed service")
+
+	s.mu.Lock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	s.mu.Unlock()
+	s.wg.Wait()
+	logger.Info(ctx, "feed service stopped")
+}
+
+func (s *service) Get(ctx context.Context, r *GetRequest) (*GetResponse, error) {
+	if err := r.validate(); err != nil {
+		return nil, common_service.NewError(common_service.ErrStatusBadRequest, err)
+	}
+
+	limit := r.Limit
+	if limit == 0 {
+		limit = maxPostsLimit
+	}
+
+	feed, err := s.getOrBuildFeed(ctx, r.UserID)
+	if err != nil {
+		return nil, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to build feed: %w", err))
+	}
+
+	posts, hasNext := feed.page(r.Offset, limit)
+
+	return &GetResponse{
+		Items:   posts,
+		HasNext: hasNext,
+	}, nil
+}
+
+func (s *service) AddPost(ctx context.Context, p *Post) {
+	s.publish(ctx, &event{eventType: eventTypePostCreated, post: p})
+}
+
+func (s *service) UpdatePost(ctx context.Context, p *Post) {
+	s.publish(ctx, &event{eventType: eventTypePostUpdated, post: p})
+}
+
+func (s *service) DeletePost(ctx context.Context, p *Post) {
+	s.publish(ctx, &event{eventType: eventTypePostDeleted, post: p})
+}
+
+func (s *service) Invalidate(_ context.Context, userID int64) {
+	s.bumpFeedVersion(userID)
+	s.feeds.Delete(getFeedKey(userID))
+}
+
+// publish passes the event to the fan-out workers without blocking the request.
+// If the workers are stopped or can't keep up, the affected feeds are invalidated instead,
+// so they will be rebuilt on the next read.
+func (s *service) publish(ctx context.Context, e *event) {
+	select {
+	case <-s.getDone():
+		s.InvalidateFollowers(ctx, e.post.AuthorID)
+		return
+	default:
+	}
+
+	select {
+	case s.events <- e:
+	default:
+		logger.WarnKV(ctx, "feed event queue is full, invalidating feeds",
+			common.PostIDTag, e.post.ID)
+		s.InvalidateFollowers(ctx, e.post.AuthorID)
+	}
+}
+
+// getDone returns the channel that's closed when the service is stopped,
+// it's nil if the service hasn't been started yet.
+func (s *service) getDone() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx == nil {
+		return nil
+	}
+
+	return s.ctx.Done()
+}
+
+// InvalidateFollowers drops all feeds if the followers can't be read.
+func (s *service) InvalidateFollowers(ctx context.Context, userID int64) {
+	followerIDs, err := s.friendRepository.GetFollowerIDs(ctx, userID)
+	if err != nil {
+		logger.ErrorKV(ctx, "failed to get followers to invalidate feeds",
+			common.UserIDTag, userID,
+			common.ErrorTag, err)
+
+		for i := range s.versions {
+			s.versions[i].Add(1)
+		}
+
+		s.feeds.Flush()
+
+		return
+	}
+
+	for _, followerID := range followerIDs {
+		s.Invalidate(ctx, followerID)
+	}
+}
+
+func (s *service) runFanOutWorker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-s.events:
+			if err := s.fanOut(ctx, e); err != nil {
+				logger.ErrorKV(ctx, "failed to fan out feed event",
+					common.PostIDTag, e.post.ID,
+					common.ErrorTag, err)
+			}
+		}
+	}
+}
+
+func (s *service) fanOut(ctx context.Context, e *event) error {
+	followerIDs, err := s.friendRepository.GetFollowerIDs(ctx, e.post.AuthorID)
+	if err != nil {
+		return fmt.Errorf("failed to get followers of user %d: %w", e.post.AuthorID, err)
+	}
+
+	for _, followerID := range followerIDs {
+		// Feeds being built can miss the event, so they won't be cached.
+		s.bumpFeedVersion(followerID)
+
+		feed, ok := s.getCachedFeed(followerID)
+		if !ok {
+			// The feed will be built with the post included on the next read.
+			continue
+		}
+
+		switch e.eventType {
+		case eventTypePostCreated:
+			feed.insert(e.post)
+		case eventTypePostUpdated:
+			feed.replace(e.post)
+		case eventTypePostDeleted:
+			feed.remove(e.post.ID)
+		}
+	}
+
+	return nil
+}
+
+func (s *service) getOrBuildFeed(ctx context.Context, userID int64) (*userFeed, error) {
+	if feed, ok := s.getCachedFeed(userID); ok {
+		return feed, nil
+	}
+
+	version := s.getFeedVersion(userID)
+
+	friendIDs, err := s.friendRepository.GetFriendIDs(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get friends of user %d: %w", userID, err)
+	}
+
+	posts, err := s.postRepository.GetLatestByAuthorIDs(ctx, friendIDs, maxFeedLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get posts of friends of user %d: %w", userID, err)
+	}
+
+	feed := newUserFeed(s.getServiceModels(posts))
+	key := getFeedKey(userID)
+	s.feeds.SetDefault(key, feed)
+
+	// The feed was invalidated or missed an event while it was being built,
+	// it's returned as is, but rebuilt on the next read.
+	if s.getFeedVersion(userID) != version {
+		s.feeds.Delete(key)
+	}
+
+	return feed, nil
+}
+
+// getFeedVersion returns the version of the user's feed, which changes when the feed is invalidated or updated.
+func (s *service) getFeedVersion(userID int64) uint64 {
+	return s.versions[uint64(userID)%feedVersionsCount].Load()
+}
+
+func (s *service) bumpFeedVersion(userID int64) {
+	s.versions[uint64(userID)%feedVersionsCount].Add(1)
+}
+
+func (s *service) getCachedFeed(userID int64) (*userFeed, bool) {
+	v, ok := s.feeds.Get(getFeedKey(userID))
+	if !ok {
+		return nil, false
+	}
+
+	feed, ok := v.(*userFeed)
+
+	return feed, ok
+}
+
+func getFeedKey(userID int64) string {
+	return strconv.FormatInt(userID, 10)
+}
@@ -0,0 +1,107 @@
+package feed
+
+import (
+	"sort"
+	"sync"
+)
+
+// userFeed is a materialized news feed of a single user.
+// Posts are ordered from the newest to the oldest and capped at maxFeedLength.
+type userFeed struct {
+	posts []*Post
+	mu    sync.RWMutex
+}
+
+// maxFeedLength defines the maximum number of posts kept in a user's feed.
+const maxFeedLength = 1000
+
+func newUserFeed(posts []*Post) *userFeed {
+	if len(posts) > maxFeedLength {
+		posts = posts[:maxFeedLength]
+	}
+
+	return &userFeed{
+		posts: posts,
+	}
+}
+
+// page returns a copy of the posts in the range [offset, offset+limit)
+// and whether there are more posts after the range.
+func (f *userFeed) page(offset, limit uint64) ([]*Post, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	total := uint64(len(f.posts))
+	if offset >= total {
+		return []*Post{}, false
+	}
+
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	result := make([]*Post, end-offset)
+	copy(result, f.posts[offset:end])
+
+	return result, end < total
+}
+
+// insert adds the post to the feed keeping the order by ID.
+func (f *userFeed) insert(p *Post) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	idx := f.search(p.ID)
+	if idx < len(f.posts) && f.posts[idx].ID == p.ID {
+		f.posts[idx] = p
+		return
+	}
+
+	if idx >= maxFeedLength {
+		return
+	}
+
+	f.posts = append(f.posts, nil)
+	copy(f.posts[idx+1:], f.posts[idx:])
+	f.posts[idx] = p
+
+	if len(f.posts) > maxFeedLength {
+		f.posts[maxFeedLength] = nil
+		f.posts = f.posts[:maxFeedLength]
+	}
+}
+
+// replace replaces the post with the same ID if it's present in the feed.
+func (f *userFeed) replace(p *Post) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	idx := f.search(p.ID)
+	if idx < len(f.posts) && f.posts[idx].ID == p.ID {
+		f.posts[idx] = p
+	}
+}
+
+// remove deletes the post with the given ID from the feed.
+func (f *userFeed) remove(id int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	idx := f.search(id)
+	if idx >= len(f.posts) || f.posts[idx].ID != id {
+		return
+	}
+
+	copy(f.posts[idx:], f.posts[idx+1:])
+	f.posts[len(f.posts)-1] = nil
+	f.posts = f.posts[:len(f.posts)-1]
+}
+
+// search returns the position of the post with the given ID
+// or the position where it should be inserted.
+func (f *userFeed) search(id int64) int {
+	return sort.Search(len(f.posts), func(i int) bool {
+		return f.posts[i].ID <= id
+	})
+}
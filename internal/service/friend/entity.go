@@ -0,0 +1,77 @@
+package friend
+
+import (
+	"fmt"
+	"time"
+
+	repo "github.com/oshokin/hive-backend/internal/repository/friend"
+)
+
+type (
+	// Friend represents a user added to another user's friend list.
+	Friend struct {
+		UserID    int64     // ID of the friend.
+		CreatedAt time.Time // Time when the friend was added.
+	}
+
+	// GetListRequest represents a request to get a list of user's friends.
+	GetListRequest struct {
+		UserID int64  // ID of the user whose friends are requested.
+		Limit  uint64 // Limit of friends to return.
+		Cursor int64  // Cursor is used for pagination.
+	}
+
+	// GetListResponse represents a response containing a list of user's friends.
+	GetListResponse struct {
+		Items   []*Friend // List of friends.
+		HasNext bool      // Indicates whether there are more items to be retrieved.
+	}
+)
+
+const maxFriendsLimit = 50
+
+func (s *service) getServiceModel(source *repo.Friendship) *Friend {
+	if source == nil {
+		return nil
+	}
+
+	return &Friend{
+		UserID:    source.FriendID,
+		CreatedAt: source.CreatedAt,
+	}
+}
+
+func (s *service) getServiceModels(source []*repo.Friendship) []*Friend {
+	result := make([]*Friend, 0, len(source))
+
+	for _, v := range source {
+		sm := s.getServiceModel(v)
+		if sm == nil {
+			continue
+		}
+
+		result = append(result, sm)
+	}
+
+	return result
+}
+
+func (r *GetListRequest) validate() error {
+	if r == nil {
+		return nil
+	}
+
+	if r.UserID <= 0 {
+		return fmt.Errorf("user ID must be greater than 0")
+	}
+
+	if r.Limit > maxFriendsLimit {
+		return fmt.Errorf("maximum friends count in one request is %d items", maxFriendsLimit)
+	}
+
+	if r.Cursor < 0 {
+		return fmt.Errorf("cursor must be greater than or equal to 0")
+	}
+
+	return nil
+}
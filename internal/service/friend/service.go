@@ -0,0 +1,164 @@
+// Package friend provides a service to manage friend lists of users.
+package friend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	friend_repo "github.com/oshokin/hive-backend/internal/repository/friend"
+	common_service "github.com/oshokin/hive-backend/internal/service/common"
+	feed_service "github.com/oshokin/hive-backend/internal/service/feed"
+	user_service "github.com/oshokin/hive-backend/internal/service/user"
+)
+
+type (
+	// Service defines the methods to manage friend lists.
+	Service interface {
+		// Add adds the friend to the user's friend list.
+		Add(ctx context.Context, userID, friendID int64) error
+		// Delete removes the friend from the user's friend list.
+		Delete(ctx context.Context, userID, friendID int64) error
+		// GetFollowerIDs gets IDs of all users who have the given user in their friend lists.
+		GetFollowerIDs(ctx context.Context, userID int64) ([]int64, error)
+		// GetFollowerIDsAmong gets IDs of the given users who have the given user in their friend lists.
+		GetFollowerIDsAmong(ctx context.Context, userID int64, candidateIDs []int64) (map[int64]struct{}, error)
+		// GetList gets a paginated list of the user's friends.
+		GetList(ctx context.Context, req *GetListRequest) (*GetListResponse, error)
+	}
+
+	service struct {
+		friendRepository friend_repo.Repository
+		userService      user_service.Service
+		feedService      feed_service.Service
+	}
+)
+
+var (
+	errInvalidFriendID = common_service.NewError(common_service.ErrStatusBadRequest,
+		errors.New("friend ID must be greater than 0"))
+	errSelfFriendship = common_service.NewError(common_service.ErrStatusBadRequest,
+		errors.New("user cannot add their own account as a friend"))
+	errFriendNotFound = common_service.NewError(common_service.ErrStatusNotFound,
+		errors.New("friend not found"))
+)
+
+// NewService returns a new instance of the friend service.
+func NewService(r friend_repo.Repository, u user_service.Service, f feed_service.Service) Service {
+	return &service{
+		friendRepository: r,
+		userService:      u,
+		feedService:      f,
+	}
+}
+
+func (s *service) Add(ctx context.Context, userID, friendID int64) error {
+	if err := s.validatePair(userID, friendID); err != nil {
+		return err
+	}
+
+	// Returns a not found error if there is no such user.
+	if _, err := s.userService.GetByID(ctx, friendID); err != nil {
+		return err
+	}
+
+	isCreated, err := s.friendRepository.Create(ctx, userID, friendID)
+	if err != nil {
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to add friend: %w", err))
+	}
+
+	if isCreated {
+		s.feedService.Invalidate(ctx, userID)
+	}
+
+	return nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, friendID int64) error {
+	if err := s.validatePair(userID, friendID); err != nil {
+		return err
+	}
+
+	isDeleted, err := s.friendRepository.Delete(ctx, userID, friendID)
+	if err != nil {
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to delete friend: %w", err))
+	}
+
+	if !isDeleted {
+		return errFriendNotFound
+	}
+
+	s.feedService.Invalidate(ctx, userID)
+
+	return nil
+}
+
+func (s *service) GetFollowerIDs(ctx context.Context, userID int64) ([]int64, error) {
+	followerIDs, err := s.friendRepository.GetFollowerIDs(ctx, userID)
+	if err != nil {
+		return nil, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to get followers: %w", err))
+	}
+
+	return followerIDs, nil
+}
+
+func (s *service) GetFollowerIDsAmong(ctx context.Context,
+	userID int64,
+	candidateIDs []int64) (map[int64]struct{}, error) {
+	result := make(map[int64]struct{})
+	if userID <= 0 || len(candidateIDs) == 0 {
+		return result, nil
+	}
+
+	followerIDs, err := s.friendRepository.FilterFollowerIDs(ctx, userID, candidateIDs)
+	if err != nil {
+		return nil, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to get followers: %w", err))
+	}
+
+	for _, v := range followerIDs {
+		result[v] = struct{}{}
+	}
+
+	return result, nil
+}
+
+func (s *service) GetList(ctx context.Context, r *GetListRequest) (*GetListResponse, error) {
+	if err := r.validate(); err != nil {
+		return nil, common_service.NewError(common_service.ErrStatusBadRequest, err)
+	}
+
+	limit := r.Limit
+	if limit == 0 {
+		limit = maxFriendsLimit
+	}
+
+	res, err := s.friendRepository.GetList(ctx, &friend_repo.GetListRequest{
+		UserID: r.UserID,
+		Limit:  limit,
+		Cursor: r.Cursor,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetListResponse{
+		Items:   s.getServiceModels(res.Items),
+		HasNext: res.HasNext,
+	}, nil
+}
+
+func (s *service) validatePair(userID, friendID int64) error {
+	if friendID <= 0 {
+		return errInvalidFriendID
+	}
+
+	if userID == friendID {
+		return errSelfFriendship
+	}
+
+	return nil
+}
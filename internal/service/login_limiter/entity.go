@@ -0,0 +1,46 @@
+package login_limiter
+
+import "time"
+
+// policy defines how failed login attempts made with the same key are throttled.
+type policy struct {
+	keyPrefix    string        // Prefix of keys the policy is applied to.
+	freeAttempts int64         // Number of failures allowed without a delay.
+	baseLockout  time.Duration // Lockout after the first failure over the free ones, it doubles with every next failure.
+	maxLockout   time.Duration // Maximum lockout.
+	resetAfter   time.Duration // Failures are forgotten if there were none for this time.
+}
+
+var (
+	emailPolicy = &policy{
+		keyPrefix:    "email:",
+		freeAttempts: 3,
+		baseLockout:  1 * time.Second,
+		maxLockout:   15 * time.Minute,
+		resetAfter:   1 * time.Hour,
+	}
+	ipPolicy = &policy{
+		keyPrefix:    "ip:",
+		freeAttempts: 20,
+		baseLockout:  1 * time.Second,
+		maxLockout:   15 * time.Minute,
+		resetAfter:   1 * time.Hour,
+	}
+)
+
+// getLockout returns for how long the key is locked after the given number of failures in a row.
+func (p *policy) getLockout(failures int64) time.Duration {
+	if failures < p.freeAttempts {
+		return 0
+	}
+
+	lockout := p.baseLockout
+	for i := p.freeAttempts; i < failures; i++ {
+		lockout *= 2
+		if lockout >= p.maxLockout {
+			return p.maxLockout
+		}
+	}
+
+	return lockout
+}
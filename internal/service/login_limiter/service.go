@@ -0,0 +1,174 @@
+// Package login_limiter provides a service to throttle login attempts per email and per client IP address.
+package login_limiter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oshokin/hive-backend/internal/common"
+	"github.com/oshokin/hive-backend/internal/logger"
+	login_attempt_repo "github.com/oshokin/hive-backend/internal/repository/login_attempt"
+)
+
+type (
+	// Service provides methods for throttling login attempts.
+	Service interface {
+		// Start starts the removal of outdated failed attempts.
+		Start(ctx context.Context)
+		// Stop stops the removal of outdated failed attempts.
+		Stop(ctx context.Context)
+		// Reserve counts a login attempt with the email from the IP address as failed before the password is checked,
+		// so that concurrent attempts can't get around the limit.
+		// It returns how long the client must wait before trying to login (0 if it can try now).
+		Reserve(ctx context.Context, email, ip string) (time.Duration, error)
+		// Reset forgets failed login attempts with the email after a successful login
+		// and takes back the attempt reserved for the IP address.
+		Reset(ctx context.Context, email, ip string) error
+	}
+
+	service struct {
+		loginAttemptRepository login_attempt_repo.Repository
+		cancel                 context.CancelFunc
+		mu                     sync.Mutex
+	}
+)
+
+const cleanupTimeout = 10 * time.Minute
+
+// NewService returns a new instance of the login limiter service.
+func NewService(r login_attempt_repo.Repository) Service {
+	return &service{
+		loginAttemptRepository: r,
+	}
+}
+
+func (s *service) Start(ctx context.Context) {
+	logger.Info(ctx, "starting login limiter service")
+
+	s.mu.Lock()
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(cleanupTimeout)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.cleanup(ctx)
+			}
+		}
+	}()
+
+	logger.Info(ctx, "login limiter service is running")
+}
+
+func (s *service) Stop(ctx context.Context) {
+	logger.Info(ctx, "shutting down login limiter service")
+	s.mu.Lock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	s.mu.Unlock()
+	logger.Info(ctx, "login limiter service stopped")
+}
+
+func (s *service) Reserve(ctx context.Context, email, ip string) (time.Duration, error) {
+	var (
+		keyValues  = s.getKeyValues(email, ip)
+		failures   = make(map[*policy]int64, len(keyValues))
+		retryAfter time.Duration
+	)
+
+	// Locked keys are rejected without counting the attempt.
+	for p, value := range keyValues {
+		a, err := s.loginAttemptRepository.Get(ctx, getKey(p, value))
+		if err != nil {
+			return 0, fmt.Errorf("failed to get failed login attempts: %w", err)
+		}
+
+		if a == nil || a.SinceLastFailure > p.resetAfter {
+			continue
+		}
+
+		if wait := p.getLockout(a.Failures) - a.SinceLastFailure; wait > retryAfter {
+			retryAfter = wait
+		}
+
+		failures[p] = a.Failures
+	}
+
+	if retryAfter > 0 {
+		return retryAfter, nil
+	}
+
+	for p, value := range keyValues {
+		a, err := s.loginAttemptRepository.RegisterFailure(ctx, getKey(p, value), p.resetAfter)
+		if err != nil {
+			return 0, fmt.Errorf("failed to register failed login attempt: %w", err)
+		}
+
+		// Concurrent attempts were counted since the check, they are ahead of this one.
+		if a.Failures <= failures[p]+1 {
+			continue
+		}
+
+		if wait := p.getLockout(a.Failures - 1); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	return retryAfter, nil
+}
+
+func (s *service) Reset(ctx context.Context, email, ip string) error {
+	if err := s.loginAttemptRepository.Delete(ctx, getKey(emailPolicy, strings.ToLower(email))); err != nil {
+		return fmt.Errorf("failed to reset failed login attempts: %w", err)
+	}
+
+	if ip == "" {
+		return nil
+	}
+
+	if err := s.loginAttemptRepository.ReleaseFailure(ctx, getKey(ipPolicy, ip)); err != nil {
+		return fmt.Errorf("failed to release failed login attempt: %w", err)
+	}
+
+	return nil
+}
+
+func (s *service) cleanup(ctx context.Context) {
+	maxResetAfter := emailPolicy.resetAfter
+	if ipPolicy.resetAfter > maxResetAfter {
+		maxResetAfter = ipPolicy.resetAfter
+	}
+
+	if err := s.loginAttemptRepository.DeleteOlderThan(ctx, maxResetAfter); err != nil {
+		logger.ErrorKV(ctx, "failed to delete outdated login attempts", common.ErrorTag, err)
+	}
+}
+
+// getKeyValues returns values to be throttled by their policies, the IP address is skipped if it's unknown.
+func (s *service) getKeyValues(email, ip string) map[*policy]string {
+	values := map[*policy]string{
+		emailPolicy: strings.ToLower(email),
+	}
+
+	if ip != "" {
+		values[ipPolicy] = ip
+	}
+
+	return values
+}
+
+func getKey(p *policy, value string) string {
+	return strings.Join([]string{p.keyPrefix, value}, "")
+}
@@ -0,0 +1,113 @@
+package post
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	repo "github.com/oshokin/hive-backend/internal/repository/post"
+	feed_service "github.com/oshokin/hive-backend/internal/service/feed"
+)
+
+// Post represents a text post written by a user.
+type Post struct {
+	ID        int64      // unique identifier of the post
+	AuthorID  int64      // ID of the user who wrote the post
+	Text      string     // text of the post
+	CreatedAt time.Time  // the time when the post was created
+	UpdatedAt *time.Time // the time when the post was last updated (nil if it was never updated)
+}
+
+// maxPostTextLength defines the maximum number of characters in a post.
+const maxPostTextLength = 5000
+
+func (s *service) getServiceModel(source *repo.Post) *Post {
+	if source == nil {
+		return nil
+	}
+
+	return &Post{
+		ID:        source.ID,
+		AuthorID:  source.AuthorID,
+		Text:      source.Text,
+		CreatedAt: source.CreatedAt,
+		UpdatedAt: source.UpdatedAt,
+	}
+}
+
+func (s *service) getRepoModel(source *Post) *repo.Post {
+	if source == nil {
+		return nil
+	}
+
+	return &repo.Post{
+		ID:        source.ID,
+		AuthorID:  source.AuthorID,
+		Text:      source.Text,
+		CreatedAt: source.CreatedAt,
+		UpdatedAt: source.UpdatedAt,
+	}
+}
+
+func (s *service) getFeedModel(source *Post) *feed_service.Post {
+	if source == nil {
+		return nil
+	}
+
+	return &feed_service.Post{
+		ID:        source.ID,
+		AuthorID:  source.AuthorID,
+		Text:      source.Text,
+		CreatedAt: source.CreatedAt,
+		UpdatedAt: source.UpdatedAt,
+	}
+}
+
+// String returns a string representation of the Post object.
+func (p *Post) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("post{id=")
+	sb.WriteString(strconv.FormatInt(p.ID, 10))
+	sb.WriteString(", author_id=")
+	sb.WriteString(strconv.FormatInt(p.AuthorID, 10))
+	sb.WriteString(", text=")
+	sb.WriteString(p.Text)
+	sb.WriteString(", created_at=")
+	sb.WriteString(p.CreatedAt.Format(time.RFC3339Nano))
+	sb.WriteString(", updated_at=")
+
+	if p.UpdatedAt == nil {
+		sb.WriteString("nil")
+	} else {
+		sb.WriteString(p.UpdatedAt.Format(time.RFC3339Nano))
+	}
+
+	sb.WriteString("}")
+
+	return sb.String()
+}
+
+func (p *Post) validate() error {
+	if p == nil {
+		return nil
+	}
+
+	if p.AuthorID <= 0 {
+		return fmt.Errorf("author ID must be greater than 0")
+	}
+
+	// The text is trimmed before validation, so the length is checked as it's stored.
+	textLength := utf8.RuneCountInString(p.Text)
+	if textLength == 0 {
+		return fmt.Errorf("text is required")
+	}
+
+	if textLength > maxPostTextLength {
+		return fmt.Errorf("text cannot be longer than %d characters", maxPostTextLength)
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+// Package post provides a service to manage posts of users.
+package post
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oshokin/hive-backend/internal/common"
+	"github.com/oshokin/hive-backend/internal/logger"
+	post_repo "github.com/oshokin/hive-backend/internal/repository/post"
+	common_service "github.com/oshokin/hive-backend/internal/service/common"
+	feed_service "github.com/oshokin/hive-backend/internal/service/feed"
+	"github.com/oshokin/hive-backend/internal/util/pubsub"
+)
+
+type (
+	// Service defines the methods to manage posts.
+	Service interface {
+		// Create creates a new post and returns its ID.
+		Create(ctx context.Context, p *Post) (int64, error)
+		// Delete deletes the post on behalf of the given user.
+		Delete(ctx context.Context, userID, id int64) error
+		// GetByID gets a post by ID.
+		GetByID(ctx context.Context, id int64) (*Post, error)
+		// Update updates the text of the post on behalf of the given user.
+		Update(ctx context.Context, userID int64, p *Post) error
+	}
+
+	service struct {
+		postRepository post_repo.Repository
+		feedService    feed_service.Service
+		createdPosts   *pubsub.Bus[*Post]
+	}
+)
+
+var (
+	errInvalidPostID = common_service.NewError(common_service.ErrStatusBadRequest,
+		errors.New("post ID must be greater than 0"))
+	errPostNotFound = common_service.NewError(common_service.ErrStatusNotFound,
+		errors.New("post not found"))
+	errPostIsNotOwned = common_service.NewError(common_service.ErrStatusForbidden,
+		errors.New("post belongs to another user"))
+
+	textUpdateFields = &post_repo.UpdateFields{
+		Text:      true,
+		UpdatedAt: true,
+	}
+)
+
+// NewService returns a new instance of the post service.
+// Every created post is published to the given bus.
+func NewService(r post_repo.Repository, f feed_service.Service, b *pubsub.Bus[*Post]) Service {
+	return &service{
+		postRepository: r,
+		feedService:    f,
+		createdPosts:   b,
+	}
+}
+
+func (s *service) Create(ctx context.Context, p *Post) (int64, error) {
+	p.Text = strings.TrimSpace(p.Text)
+	if err := p.validate(); err != nil {
+		return 0, common_service.NewError(common_service.ErrStatusBadRequest, err)
+	}
+
+	rm := s.getRepoModel(p)
+
+	postID, err := s.postRepository.Create(ctx, rm)
+	if err != nil {
+		return 0, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to create post: %w", err))
+	}
+
+	p.ID = postID
+	p.CreatedAt = rm.CreatedAt
+
+	s.feedService.AddPost(ctx, s.getFeedModel(p))
+
+	if droppedCount := s.createdPosts.Publish(p); droppedCount > 0 {
+		logger.WarnKV(ctx, "created post was not delivered to some subscribers",
+			common.PostIDTag, postID,
+			common.DroppedCountTag, droppedCount)
+	}
+
+	return postID, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, id int64) error {
+	p, err := s.getOwnedPost(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	isDeleted, err := s.postRepository.Delete(ctx, id)
+	if err != nil {
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to delete post: %w", err))
+	}
+
+	if !isDeleted {
+		return errPostNotFound
+	}
+
+	s.feedService.DeletePost(ctx, s.getFeedModel(p))
+
+	return nil
+}
+
+func (s *service) GetByID(ctx context.Context, id int64) (*Post, error) {
+	if id <= 0 {
+		return nil, errInvalidPostID
+	}
+
+	p, err := s.postRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to read post: %w", err))
+	}
+
+	if p == nil {
+		return nil, errPostNotFound
+	}
+
+	return s.getServiceModel(p), nil
+}
+
+func (s *service) Update(ctx context.Context, userID int64, p *Post) error {
+	existing, err := s.getOwnedPost(ctx, userID, p.ID)
+	if err != nil {
+		return err
+	}
+
+	updatedAt := time.Now()
+	existing.Text = strings.TrimSpace(p.Text)
+	existing.UpdatedAt = &updatedAt
+
+	if err = existing.validate(); err != nil {
+		return common_service.NewError(common_service.ErrStatusBadRequest, err)
+	}
+
+	err = s.postRepository.Update(ctx, s.getRepoModel(existing), textUpdateFields)
+	if err != nil {
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to update post: %w", err))
+	}
+
+	*p = *existing
+
+	s.feedService.UpdatePost(ctx, s.getFeedModel(existing))
+
+	return nil
+}
+
+func (s *service) getOwnedPost(ctx context.Context, userID, id int64) (*Post, error) {
+	p, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.AuthorID != userID {
+		return nil, errPostIsNotOwned
+	}
+
+	return p, nil
+}
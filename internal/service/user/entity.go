@@ -1,10 +1,12 @@
 package user
 
 import (
+	"encoding/base64"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	validator "github.com/asaskevich/govalidator"
 	user_repo "github.com/oshokin/hive-backend/internal/repository/user"
@@ -24,20 +26,106 @@ type (
 		Birthdate    time.Time
 		Gender       GenderType
 		Interests    string
+		Role         RoleType
+
+		EmailVisibility     VisibilityType
+		BirthdateVisibility VisibilityType
+		InterestsVisibility VisibilityType
+	}
+
+	// UpdateFields represents the set of user profile fields to update.
+	UpdateFields struct {
+		CityID    bool
+		FirstName bool
+		LastName  bool
+		Birthdate bool
+		Gender    bool
+		Interests bool
+
+		EmailVisibility     bool
+		BirthdateVisibility bool
+		InterestsVisibility bool
+	}
+
+	// UpdateRequest represents a request to update
+	// the given fields of the profile of the user with the ID of User.
+	UpdateRequest struct {
+		User   *User
+		Fields *UpdateFields
 	}
 
 	// LoginCredentials represents the user's login credentials
-	// with an email and password.
+	// with an email and password, and the IP address of the client they're sent from.
 	LoginCredentials struct {
 		Email    string
 		Password string
+		IP       string
 	}
 
 	// LoginData represents the data required
-	// for user login such as ID and password hash.
+	// for user login such as ID, password hash and role.
 	LoginData struct {
 		ID           int64
 		PasswordHash string
+		Role         RoleType
+	}
+
+	// ChangePasswordRequest represents a request to change the password
+	// of a user who knows the current one.
+	ChangePasswordRequest struct {
+		UserID      int64
+		OldPassword string
+		NewPassword string
+	}
+
+	// ResetPasswordRequest represents a request to set a new password
+	// using a token received by a user who forgot the current one.
+	ResetPasswordRequest struct {
+		Token       string
+		NewPassword string
+	}
+
+	// SearchRequest represents a request to search users by a free-form query
+	// matched against their names and interests, and by optional filters.
+	// Either the query or at least one filter is required, ages are in full years.
+	SearchRequest struct {
+		Query            string
+		CityIDs          []int16
+		Gender           GenderType
+		MinAge           uint8
+		MaxAge           uint8
+		InterestKeywords []string
+		Limit            uint64
+		Cursor           string
+	}
+
+	// SearchResponse represents the response to a request to search users by a free-form query.
+	// NextCursor is passed in the next request to get the next page.
+	// Facets are counted for the first page only.
+	SearchResponse struct {
+		Items      []*User
+		HasNext    bool
+		NextCursor string
+		Facets     *SearchFacets
+	}
+
+	// SearchFacets represents the numbers of users matching a search request per city and gender.
+	// Each facet ignores the filter by its own field, so that it shows what other values would give.
+	SearchFacets struct {
+		Cities  []*CityFacet
+		Genders []*GenderFacet
+	}
+
+	// CityFacet represents the number of matching users living in a city.
+	CityFacet struct {
+		CityID int16
+		Count  int64
+	}
+
+	// GenderFacet represents the number of matching users of a gender.
+	GenderFacet struct {
+		Gender GenderType
+		Count  int64
 	}
 
 	// SearchByNamePrefixesRequest represents a request to search users
@@ -58,6 +146,12 @@ type (
 
 	// GenderType represents the gender of a user.
 	GenderType string
+
+	// RoleType represents the role of a user.
+	RoleType string
+
+	// VisibilityType represents who can see a private field of a user profile.
+	VisibilityType string
 )
 
 // GenderType can have one of three possible values.
@@ -67,7 +161,39 @@ const (
 	GenderUnknown GenderType = "UNKNOWN"
 )
 
-const maxUsersLimit = 50
+// RoleType can have one of two possible values.
+const (
+	RoleUser  RoleType = "USER"
+	RoleAdmin RoleType = "ADMIN"
+)
+
+// VisibilityType can have one of three possible values.
+const (
+	VisibilityPublic  VisibilityType = "PUBLIC"
+	VisibilityFriends VisibilityType = "FRIENDS"
+	VisibilityOnlyMe  VisibilityType = "ONLY_ME"
+)
+
+const (
+	maxUsersLimit          = 50
+	maxSearchQueryLength   = 100
+	maxSearchCitiesCount   = 50
+	maxSearchKeywordsCount = 10
+	maxSearchKeywordLength = 50
+	maxSearchAge           = 150
+	searchCursorSep        = ":"
+)
+
+// allProfileFields is the set of user profile fields, which are required on user creation.
+// Privacy settings aren't among them, they're set to defaults on creation.
+var allProfileFields = &UpdateFields{
+	CityID:    true,
+	FirstName: true,
+	LastName:  true,
+	Birthdate: true,
+	Gender:    true,
+	Interests: true,
+}
 
 func (s *service) getServiceModel(source *user_repo.User) *User {
 	if source == nil {
@@ -83,6 +209,11 @@ func (s *service) getServiceModel(source *user_repo.User) *User {
 		Birthdate: source.Birthdate,
 		Gender:    GenderType(source.Gender),
 		Interests: source.Interests,
+		Role:      RoleType(source.Role),
+
+		EmailVisibility:     VisibilityType(source.EmailVisibility),
+		BirthdateVisibility: VisibilityType(source.BirthdateVisibility),
+		InterestsVisibility: VisibilityType(source.InterestsVisibility),
 	}
 }
 
@@ -115,6 +246,25 @@ func (s *service) getRepoModel(source *User) *user_repo.User {
 		Birthdate:    source.Birthdate,
 		Gender:       string(source.Gender),
 		Interests:    source.Interests,
+
+		EmailVisibility:     string(source.EmailVisibility),
+		BirthdateVisibility: string(source.BirthdateVisibility),
+		InterestsVisibility: string(source.InterestsVisibility),
+	}
+}
+
+func (s *service) getRepoUpdateFields(source *UpdateFields) *user_repo.UpdateFields {
+	return &user_repo.UpdateFields{
+		CityID:    source.CityID,
+		FirstName: source.FirstName,
+		LastName:  source.LastName,
+		Birthdate: source.Birthdate,
+		Gender:    source.Gender,
+		Interests: source.Interests,
+
+		EmailVisibility:     source.EmailVisibility,
+		BirthdateVisibility: source.BirthdateVisibility,
+		InterestsVisibility: source.InterestsVisibility,
 	}
 }
 
@@ -175,29 +325,66 @@ func (u *User) validate() error {
 		return fmt.Errorf("password is required")
 	}
 
-	if u.CityID <= 0 {
+	return u.validateProfile(allProfileFields)
+}
+
+// validateProfile validates the given profile fields of the user.
+func (u *User) validateProfile(fields *UpdateFields) error {
+	if fields.CityID && u.CityID <= 0 {
 		return fmt.Errorf("invalid city ID")
 	}
 
-	if len(u.FirstName) == 0 {
+	if fields.FirstName && len(u.FirstName) == 0 {
 		return fmt.Errorf("first name is required")
 	}
 
-	if len(u.LastName) == 0 {
+	if fields.LastName && len(u.LastName) == 0 {
 		return fmt.Errorf("last name is required")
 	}
 
-	if u.Birthdate.IsZero() {
+	if fields.Birthdate && u.Birthdate.IsZero() {
 		return fmt.Errorf("birthdate is required")
 	}
 
-	if u.Gender != GenderMale && u.Gender != GenderFemale && u.Gender != GenderUnknown {
+	if fields.Gender && u.Gender != GenderMale && u.Gender != GenderFemale && u.Gender != GenderUnknown {
 		return fmt.Errorf("invalid gender")
 	}
 
+	if fields.EmailVisibility && !u.EmailVisibility.isValid() {
+		return fmt.Errorf("invalid email visibility")
+	}
+
+	if fields.BirthdateVisibility && !u.BirthdateVisibility.isValid() {
+		return fmt.Errorf("invalid birthdate visibility")
+	}
+
+	if fields.InterestsVisibility && !u.InterestsVisibility.isValid() {
+		return fmt.Errorf("invalid interests visibility")
+	}
+
 	return nil
 }
 
+func (v VisibilityType) isValid() bool {
+	return v == VisibilityPublic || v == VisibilityFriends || v == VisibilityOnlyMe
+}
+
+func (r *UpdateRequest) validate() error {
+	if r == nil {
+		return nil
+	}
+
+	if r.User == nil || r.User.ID <= 0 {
+		return fmt.Errorf("user ID must be greater than 0")
+	}
+
+	if r.Fields == nil || *r.Fields == (UpdateFields{}) {
+		return fmt.Errorf("no fields to update")
+	}
+
+	return r.User.validateProfile(r.Fields)
+}
+
 func (cr *LoginCredentials) validate() error {
 	if cr == nil {
 		return nil
@@ -214,6 +401,201 @@ func (cr *LoginCredentials) validate() error {
 	return nil
 }
 
+func (r *ChangePasswordRequest) validate() error {
+	if r == nil {
+		return nil
+	}
+
+	if r.UserID <= 0 {
+		return fmt.Errorf("user ID must be greater than 0")
+	}
+
+	if len(r.OldPassword) == 0 {
+		return fmt.Errorf("old password is required")
+	}
+
+	if len(r.NewPassword) == 0 {
+		return fmt.Errorf("new password is required")
+	}
+
+	return nil
+}
+
+func (r *ResetPasswordRequest) validate() error {
+	if r == nil {
+		return nil
+	}
+
+	if len(r.Token) == 0 {
+		return fmt.Errorf("token is required")
+	}
+
+	if len(r.NewPassword) == 0 {
+		return fmt.Errorf("new password is required")
+	}
+
+	return nil
+}
+
+func (r *SearchRequest) validate() error {
+	if r == nil {
+		return nil
+	}
+
+	if len(strings.TrimSpace(r.Query)) == 0 && !r.hasFilters() {
+		return fmt.Errorf("query or at least one filter is required")
+	}
+
+	if utf8.RuneCountInString(r.Query) > maxSearchQueryLength {
+		return fmt.Errorf("query cannot be longer than %d characters", maxSearchQueryLength)
+	}
+
+	if len(r.CityIDs) > maxSearchCitiesCount {
+		return fmt.Errorf("cannot filter by more than %d cities", maxSearchCitiesCount)
+	}
+
+	for _, v := range r.CityIDs {
+		if v <= 0 {
+			return fmt.Errorf("invalid city ID")
+		}
+	}
+
+	if r.Gender != "" && r.Gender != GenderMale && r.Gender != GenderFemale && r.Gender != GenderUnknown {
+		return fmt.Errorf("invalid gender")
+	}
+
+	if r.MinAge > maxSearchAge || r.MaxAge > maxSearchAge {
+		return fmt.Errorf("age cannot be greater than %d", maxSearchAge)
+	}
+
+	if r.MaxAge > 0 && r.MinAge > r.MaxAge {
+		return fmt.Errorf("min age cannot be greater than max age")
+	}
+
+	if len(r.InterestKeywords) > maxSearchKeywordsCount {
+		return fmt.Errorf("cannot filter by more than %d interest keywords", maxSearchKeywordsCount)
+	}
+
+	for _, v := range r.InterestKeywords {
+		if len(strings.TrimSpace(v)) == 0 {
+			return fmt.Errorf("interest keyword cannot be empty")
+		}
+
+		if utf8.RuneCountInString(v) > maxSearchKeywordLength {
+			return fmt.Errorf("interest keyword cannot be longer than %d characters", maxSearchKeywordLength)
+		}
+	}
+
+	if r.Limit > maxUsersLimit {
+		return fmt.Errorf("limit cannot be greater than %d", maxUsersLimit)
+	}
+
+	return nil
+}
+
+func (r *SearchRequest) hasFilters() bool {
+	return len(r.CityIDs) > 0 ||
+		r.Gender != "" ||
+		r.MinAge > 0 ||
+		r.MaxAge > 0 ||
+		len(r.InterestKeywords) > 0
+}
+
+// getBirthdateRange converts the age range of the request to the range of birthdates as of now.
+// Returns nil for the bounds that aren't set.
+func (r *SearchRequest) getBirthdateRange(now time.Time) (*time.Time, *time.Time) {
+	var (
+		today                      = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		minBirthdate, maxBirthdate *time.Time
+	)
+
+	if r.MaxAge > 0 {
+		// The oldest users turn MaxAge+1 tomorrow.
+		v := today.AddDate(-int(r.MaxAge)-1, 0, 1)
+		minBirthdate = &v
+	}
+
+	if r.MinAge > 0 {
+		v := today.AddDate(-int(r.MinAge), 0, 0)
+		maxBirthdate = &v
+	}
+
+	return minBirthdate, maxBirthdate
+}
+
+func (s *service) getServiceSearchFacets(source *user_repo.SearchFacets) *SearchFacets {
+	if source == nil {
+		return nil
+	}
+
+	result := &SearchFacets{
+		Cities:  make([]*CityFacet, 0, len(source.Cities)),
+		Genders: make([]*GenderFacet, 0, len(source.Genders)),
+	}
+
+	for _, v := range source.Cities {
+		result.Cities = append(result.Cities, &CityFacet{
+			CityID: v.CityID,
+			Count:  v.Count,
+		})
+	}
+
+	for _, v := range source.Genders {
+		result.Genders = append(result.Genders, &GenderFacet{
+			Gender: GenderType(v.Gender),
+			Count:  v.Count,
+		})
+	}
+
+	return result
+}
+
+// encodeSearchCursor returns an opaque cursor pointing to the position in search results.
+func encodeSearchCursor(c *user_repo.SearchCursor) string {
+	if c == nil {
+		return ""
+	}
+
+	raw := strings.Join([]string{
+		strconv.FormatFloat(float64(c.Relevance), 'g', -1, 32),
+		strconv.FormatInt(c.ID, 10),
+	}, searchCursorSep)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeSearchCursor parses a cursor made by encodeSearchCursor, an empty cursor points to the first page.
+func decodeSearchCursor(cursor string) (*user_repo.SearchCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	relevance, id, found := strings.Cut(string(raw), searchCursorSep)
+	if !found {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	parsedRelevance, err := strconv.ParseFloat(relevance, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	parsedID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || parsedID <= 0 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &user_repo.SearchCursor{
+		Relevance: float32(parsedRelevance),
+		ID:        parsedID,
+	}, nil
+}
+
 func (r *SearchByNamePrefixesRequest) validate() error {
 	if r == nil {
 		return nil
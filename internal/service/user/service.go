@@ -2,19 +2,27 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	validator "github.com/asaskevich/govalidator"
 	gofakeit "github.com/brianvoe/gofakeit/v6"
 	"github.com/oshokin/hive-backend/internal/common"
+	"github.com/oshokin/hive-backend/internal/logger"
+	"github.com/oshokin/hive-backend/internal/notifier"
+	password_reset_repo "github.com/oshokin/hive-backend/internal/repository/password_reset"
 	user_repo "github.com/oshokin/hive-backend/internal/repository/user"
 	city_service "github.com/oshokin/hive-backend/internal/service/city"
 	common_service "github.com/oshokin/hive-backend/internal/service/common"
+	login_limiter_service "github.com/oshokin/hive-backend/internal/service/login_limiter"
+	password_hasher "github.com/oshokin/hive-backend/internal/util/password_hasher"
 	rus_name_gen "github.com/oshokin/russian-name-generator"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type (
@@ -30,22 +38,43 @@ type (
 		GenerateRandomData(ctx context.Context, count int64) ([]*User, error)
 		// Get a user by ID.
 		GetByID(ctx context.Context, id int64) (*User, error)
-		// Get a user's ID by their login credentials.
-		GetIDByLoginCredentials(ctx context.Context, creds *LoginCredentials) (int64, error)
+		// Update the given profile fields of a user.
+		// Returns the updated user.
+		Update(ctx context.Context, req *UpdateRequest) (*User, error)
+		// Delete marks a user as deleted, the user is purged after the retention period.
+		Delete(ctx context.Context, id int64) error
+		// Get a user's login data by their login credentials.
+		GetLoginDataByCredentials(ctx context.Context, creds *LoginCredentials) (*LoginData, error)
+		// Search for users by a free-form query sorted by relevance.
+		Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error)
 		// Search for users by name prefixes.
 		SearchByNamePrefixes(ctx context.Context, req *SearchByNamePrefixesRequest) (*SearchByNamePrefixesResponse, error)
+		// Change the password of a user who knows the current one.
+		ChangePassword(ctx context.Context, req *ChangePasswordRequest) error
+		// Send a password reset token to the user with the given email.
+		// Unknown emails are silently ignored, so that registered emails can't be found out.
+		RequestPasswordReset(ctx context.Context, email string) error
+		// Set a new password using a password reset token.
+		// Returns the ID of the user whose password was changed.
+		ResetPassword(ctx context.Context, req *ResetPasswordRequest) (int64, error)
 	}
 
 	service struct {
-		userRepository   user_repo.Repository
-		cityService      city_service.Service
-		fakeUserPassword string
+		userRepository          user_repo.Repository
+		passwordResetRepository password_reset_repo.Repository
+		cityService             city_service.Service
+		loginLimiterService     login_limiter_service.Service
+		notifier                notifier.Notifier
+		passwordHasher          password_hasher.PasswordHasher
+		fakeUserPassword        string
 	}
 )
 
 const (
-	minAgeOfRandomUser = 10
-	maxAgeOfRandomUser = 75
+	minAgeOfRandomUser       = 10
+	maxAgeOfRandomUser       = 75
+	passwordResetTokenLength = 32
+	passwordResetTokenTTL    = 1 * time.Hour
 )
 
 var (
@@ -57,14 +86,29 @@ var (
 		errors.New("user not found"))
 	errInvalidCredentials = common_service.NewError(common_service.ErrStatusBadRequest,
 		errors.New("invalid email or password"))
+	errTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+	errInvalidOldPassword   = common_service.NewError(common_service.ErrStatusBadRequest,
+		errors.New("invalid old password"))
+	errInvalidPasswordResetToken = common_service.NewError(common_service.ErrStatusBadRequest,
+		errors.New("password reset token is invalid, expired or already used"))
 )
 
 // NewService returns a new instance of the user service.
-func NewService(r user_repo.Repository, c city_service.Service, f string) Service {
+func NewService(r user_repo.Repository,
+	p password_reset_repo.Repository,
+	c city_service.Service,
+	l login_limiter_service.Service,
+	n notifier.Notifier,
+	h password_hasher.PasswordHasher,
+	f string) Service {
 	return &service{
-		userRepository:   r,
-		cityService:      c,
-		fakeUserPassword: f,
+		userRepository:          r,
+		passwordResetRepository: p,
+		cityService:             c,
+		loginLimiterService:     l,
+		notifier:                n,
+		passwordHasher:          h,
+		fakeUserPassword:        f,
 	}
 }
 
@@ -98,12 +142,12 @@ func (s *service) Create(ctx context.Context, u *User) (int64, error) {
 		return 0, errEmailIsAlreadyTaken
 	}
 
-	passwordHash, err := s.hashPassword(u.Password)
+	passwordHash, err := s.passwordHasher.Hash(u.Password)
 	if err != nil {
 		return 0, fmt.Errorf("failed to hash password: %w", err)
 	}
 
-	u.PasswordHash = string(passwordHash)
+	u.PasswordHash = passwordHash
 
 	userID, err := s.userRepository.Create(ctx, s.getRepoModel(u))
 	if err != nil {
@@ -229,32 +273,188 @@ func (s *service) GetByID(ctx context.Context, id int64) (*User, error) {
 	return s.getServiceModel(u), nil
 }
 
-func (s *service) GetIDByLoginCredentials(ctx context.Context, creds *LoginCredentials) (int64, error) {
+func (s *service) Update(ctx context.Context, req *UpdateRequest) (*User, error) {
+	if err := req.validate(); err != nil {
+		return nil, common_service.NewError(common_service.ErrStatusBadRequest, err)
+	}
+
+	if req.Fields.CityID {
+		cityID := req.User.CityID
+
+		city, err := s.cityService.GetByID(ctx, cityID)
+		if err != nil {
+			return nil, common_service.NewError(common_service.ErrStatusInternalError,
+				fmt.Errorf("failed to check if city exists by ID: %w", err))
+		}
+
+		if city == nil {
+			return nil, common_service.NewError(common_service.ErrStatusBadRequest,
+				fmt.Errorf("city with ID %d is not found", cityID))
+		}
+	}
+
+	repoModel := s.getRepoModel(req.User)
+	repoModel.ID = req.User.ID
+
+	u, err := s.userRepository.Update(ctx, repoModel, s.getRepoUpdateFields(req.Fields))
+	if err != nil {
+		return nil, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to update user: %w", err))
+	}
+
+	if u == nil {
+		return nil, errUserNotFound
+	}
+
+	return s.getServiceModel(u), nil
+}
+
+func (s *service) Delete(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return errInvalidUserID
+	}
+
+	isDeleted, err := s.userRepository.Delete(ctx, id)
+	if err != nil {
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to delete user: %w", err))
+	}
+
+	if !isDeleted {
+		return errUserNotFound
+	}
+
+	return nil
+}
+
+func (s *service) GetLoginDataByCredentials(ctx context.Context, creds *LoginCredentials) (*LoginData, error) {
 	if err := creds.validate(); err != nil {
-		return 0, common_service.NewError(common_service.ErrStatusBadRequest, err)
+		return nil, common_service.NewError(common_service.ErrStatusBadRequest, err)
+	}
+
+	// The attempt is counted as failed before reading the password hash,
+	// so that throttled attempts, including concurrent ones, don't waste CPU on hashing.
+	retryAfter, err := s.loginLimiterService.Reserve(ctx, creds.Email, creds.IP)
+	if err != nil {
+		return nil, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to check login attempts: %w", err))
+	}
+
+	if retryAfter > 0 {
+		return nil, common_service.NewTooManyRequestsError(retryAfter, errTooManyLoginAttempts)
 	}
 
 	loginData, err := s.userRepository.GetLoginDataByEmail(ctx, creds.Email)
 	if err != nil {
-		return 0, common_service.NewError(common_service.ErrStatusInternalError,
+		return nil, common_service.NewError(common_service.ErrStatusInternalError,
 			fmt.Errorf("failed to read user info: %w", err))
 	}
 
 	if loginData == nil {
-		return 0, errInvalidCredentials
+		return nil, errInvalidCredentials
 	}
 
-	isPasswordCorrect, err := s.isPasswordCorrect(loginData.PasswordHash, creds.Password)
+	isPasswordCorrect, err := s.passwordHasher.Verify(loginData.PasswordHash, creds.Password)
 	if err != nil {
-		return 0, common_service.NewError(common_service.ErrStatusInternalError,
+		return nil, common_service.NewError(common_service.ErrStatusInternalError,
 			fmt.Errorf("failed to check password: %w", err))
 	}
 
 	if !isPasswordCorrect {
-		return 0, errInvalidCredentials
+		return nil, errInvalidCredentials
+	}
+
+	if err = s.loginLimiterService.Reset(ctx, creds.Email, creds.IP); err != nil {
+		logger.ErrorKV(ctx, "failed to reset login attempts",
+			common.UserIDTag, loginData.ID,
+			common.ErrorTag, err)
+	}
+
+	if s.passwordHasher.NeedsRehash(loginData.PasswordHash) {
+		s.rehashPassword(ctx, loginData.ID, creds.Password)
+	}
+
+	return &LoginData{
+		ID:   loginData.ID,
+		Role: RoleType(loginData.Role),
+	}, nil
+}
+
+// rehashPassword upgrades the password hash made with outdated algorithm or parameters.
+// The password is correct anyway, so an error is only logged.
+func (s *service) rehashPassword(ctx context.Context, userID int64, password string) {
+	passwordHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		logger.ErrorKV(ctx, "failed to rehash password",
+			common.UserIDTag, userID,
+			common.ErrorTag, err)
+
+		return
 	}
 
-	return loginData.ID, nil
+	if _, err = s.userRepository.UpdatePasswordHash(ctx, userID, passwordHash); err != nil {
+		logger.ErrorKV(ctx, "failed to save rehashed password",
+			common.UserIDTag, userID,
+			common.ErrorTag, err)
+	}
+}
+
+func (s *service) Search(ctx context.Context, r *SearchRequest) (*SearchResponse, error) {
+	if err := r.validate(); err != nil {
+		return nil, common_service.NewError(common_service.ErrStatusBadRequest, err)
+	}
+
+	cursor, err := decodeSearchCursor(r.Cursor)
+	if err != nil {
+		return nil, common_service.NewError(common_service.ErrStatusBadRequest, err)
+	}
+
+	limit := r.Limit
+	if limit == 0 {
+		limit = maxUsersLimit
+	}
+
+	minBirthdate, maxBirthdate := r.getBirthdateRange(time.Now())
+
+	keywords := make([]string, 0, len(r.InterestKeywords))
+	for _, v := range r.InterestKeywords {
+		keywords = append(keywords, strings.TrimSpace(v))
+	}
+
+	repoRequest := &user_repo.SearchRequest{
+		Query:            strings.TrimSpace(r.Query),
+		CityIDs:          r.CityIDs,
+		Gender:           string(r.Gender),
+		MinBirthdate:     minBirthdate,
+		MaxBirthdate:     maxBirthdate,
+		InterestKeywords: keywords,
+		Limit:            limit,
+		Cursor:           cursor,
+	}
+
+	res, err := s.userRepository.Search(ctx, repoRequest)
+	if err != nil {
+		return nil, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to search users: %w", err))
+	}
+
+	result := &SearchResponse{
+		Items:      s.getServiceModels(res.Items),
+		HasNext:    res.HasNext,
+		NextCursor: encodeSearchCursor(res.NextCursor),
+	}
+
+	if cursor == nil {
+		facets, err := s.userRepository.GetSearchFacets(ctx, repoRequest)
+		if err != nil {
+			return nil, common_service.NewError(common_service.ErrStatusInternalError,
+				fmt.Errorf("failed to count search facets: %w", err))
+		}
+
+		result.Facets = s.getServiceSearchFacets(facets)
+	}
+
+	return result, nil
 }
 
 func (s *service) SearchByNamePrefixes(ctx context.Context,
@@ -285,26 +485,144 @@ func (s *service) SearchByNamePrefixes(ctx context.Context,
 	}, nil
 }
 
-func (s *service) hashPassword(password string) ([]byte, error) {
-	hashBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+func (s *service) ChangePassword(ctx context.Context, r *ChangePasswordRequest) error {
+	if err := r.validate(); err != nil {
+		return common_service.NewError(common_service.ErrStatusBadRequest, err)
+	}
+
+	loginData, err := s.userRepository.GetLoginDataByID(ctx, r.UserID)
 	if err != nil {
-		return nil, err
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to read user info: %w", err))
+	}
+
+	if loginData == nil {
+		return errUserNotFound
+	}
+
+	isPasswordCorrect, err := s.passwordHasher.Verify(loginData.PasswordHash, r.OldPassword)
+	if err != nil {
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to check password: %w", err))
 	}
 
-	return hashBytes, nil
+	if !isPasswordCorrect {
+		return errInvalidOldPassword
+	}
+
+	return s.setPassword(ctx, r.UserID, r.NewPassword)
 }
 
-func (s *service) isPasswordCorrect(passwordHash, password string) (bool, error) {
-	err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password))
-	if err == nil {
-		return true, nil
+func (s *service) RequestPasswordReset(ctx context.Context, email string) error {
+	if !validator.IsEmail(email) {
+		return common_service.NewError(common_service.ErrStatusBadRequest,
+			errors.New("invalid email format"))
+	}
+
+	loginData, err := s.userRepository.GetLoginDataByEmail(ctx, email)
+	if err != nil {
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to read user info: %w", err))
 	}
 
-	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-		return false, nil
+	if loginData == nil {
+		return nil
 	}
 
-	return false, err
+	token, err := generatePasswordResetToken()
+	if err != nil {
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to generate password reset token: %w", err))
+	}
+
+	expiresAt := time.Now().Add(passwordResetTokenTTL)
+
+	err = s.passwordResetRepository.Create(ctx, &password_reset_repo.Token{
+		TokenHash: hashPasswordResetToken(token),
+		UserID:    loginData.ID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to save password reset token: %w", err))
+	}
+
+	err = s.notifier.Notify(ctx, &notifier.Message{
+		UserID:  loginData.ID,
+		Email:   email,
+		Subject: "Password reset",
+		Text: fmt.Sprintf("Use the token %s to reset your password, it expires at %s.",
+			token,
+			expiresAt.Format(time.RFC3339)),
+	})
+	if err != nil {
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to send password reset token: %w", err))
+	}
+
+	return nil
+}
+
+func (s *service) ResetPassword(ctx context.Context, r *ResetPasswordRequest) (int64, error) {
+	if err := r.validate(); err != nil {
+		return 0, common_service.NewError(common_service.ErrStatusBadRequest, err)
+	}
+
+	userID, err := s.passwordResetRepository.Consume(ctx, hashPasswordResetToken(r.Token))
+	if err != nil {
+		return 0, common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to use password reset token: %w", err))
+	}
+
+	if userID == 0 {
+		return 0, errInvalidPasswordResetToken
+	}
+
+	if err = s.setPassword(ctx, userID, r.NewPassword); err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// setPassword replaces the password of the user and invalidates all password reset tokens of the user.
+func (s *service) setPassword(ctx context.Context, userID int64, password string) error {
+	passwordHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to hash password: %w", err))
+	}
+
+	isUpdated, err := s.userRepository.UpdatePasswordHash(ctx, userID, passwordHash)
+	if err != nil {
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to update password: %w", err))
+	}
+
+	if !isUpdated {
+		return errUserNotFound
+	}
+
+	if err = s.passwordResetRepository.DeleteByUserID(ctx, userID); err != nil {
+		return common_service.NewError(common_service.ErrStatusInternalError,
+			fmt.Errorf("failed to delete password reset tokens: %w", err))
+	}
+
+	return nil
+}
+
+func generatePasswordResetToken() (string, error) {
+	b := make([]byte, passwordResetTokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func hashPasswordResetToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
 }
 
 func (s *service) validateBatch(ctx context.Context, sourceList []*User) ([]*User, map[*User]error, error) {
@@ -358,18 +676,13 @@ func (s *service) validateBatch(ctx context.Context, sourceList []*User) ([]*Use
 			fmt.Errorf("failed to check if cities exist by ID: %w", err))
 	}
 
-	var hashedBytes []byte
-
-	hashedBytes, err = s.hashPassword(s.fakeUserPassword)
+	passwordHash, err := s.passwordHasher.Hash(s.fakeUserPassword)
 	if err != nil {
 		return nil, nil, common_service.NewError(common_service.ErrStatusInternalError,
 			fmt.Errorf("failed to hash password: %w", err))
 	}
 
-	var (
-		passwordHash = string(hashedBytes)
-		i            int
-	)
+	var i int
 
 	for _, u := range validList {
 		email := u.Email
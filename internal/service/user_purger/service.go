@@ -0,0 +1,90 @@
+// Package user_purger provides a service to permanently remove deleted users after the retention period.
+package user_purger
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/oshokin/hive-backend/internal/common"
+	"github.com/oshokin/hive-backend/internal/logger"
+	user_repo "github.com/oshokin/hive-backend/internal/repository/user"
+)
+
+type (
+	// Service provides methods for purging deleted users.
+	Service interface {
+		// Start starts the periodic removal of deleted users.
+		Start(ctx context.Context)
+		// Stop stops the periodic removal of deleted users.
+		Stop(ctx context.Context)
+	}
+
+	service struct {
+		userRepository user_repo.Repository
+		retention      time.Duration
+		cancel         context.CancelFunc
+		mu             sync.Mutex
+	}
+)
+
+const purgeTimeout = 1 * time.Hour
+
+// NewService returns a new instance of the user purger service,
+// which removes users deleted more than the retention period ago.
+func NewService(r user_repo.Repository, retention time.Duration) Service {
+	return &service{
+		userRepository: r,
+		retention:      retention,
+	}
+}
+
+func (s *service) Start(ctx context.Context) {
+	logger.Info(ctx, "starting user purger service")
+
+	s.mu.Lock()
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(purgeTimeout)
+		defer ticker.Stop()
+
+		s.purge(ctx)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.purge(ctx)
+			}
+		}
+	}()
+
+	logger.Info(ctx, "user purger service is running")
+}
+
+func (s *service) Stop(ctx context.Context) {
+	logger.Info(ctx, "shutting down user purger service")
+	s.mu.Lock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	s.mu.Unlock()
+	logger.Info(ctx, "user purger service stopped")
+}
+
+func (s *service) purge(ctx context.Context) {
+	purgedCount, err := s.userRepository.PurgeDeleted(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		logger.ErrorKV(ctx, "failed to purge deleted users", common.ErrorTag, err)
+		return
+	}
+
+	if purgedCount > 0 {
+		logger.InfoKV(ctx, "deleted users are purged", common.PurgedCountTag, purgedCount)
+	}
+}
@@ -0,0 +1,71 @@
+package keyring
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"sort"
+)
+
+type (
+	// JWKS is a JSON Web Key Set (RFC 7517) with public keys of the keyring.
+	JWKS struct {
+		Keys []*JWK `json:"keys"`
+	}
+
+	// JWK is a JSON Web Key (RFC 7517, RFC 8037).
+	JWK struct {
+		KeyType   string `json:"kty"`
+		KeyID     string `json:"kid"`
+		Algorithm string `json:"alg"`
+		Use       string `json:"use"`
+		N         string `json:"n,omitempty"`   // RSA modulus
+		E         string `json:"e,omitempty"`   // RSA public exponent
+		Curve     string `json:"crv,omitempty"` // Curve of the OKP key
+		X         string `json:"x,omitempty"`   // OKP public key
+	}
+)
+
+// JWKS returns public keys of the keyring, so that other services could verify tokens.
+// HS256 keys are secret and never published.
+func (kr *Keyring) JWKS() *JWKS {
+	res := &JWKS{
+		Keys: make([]*JWK, 0, len(kr.keys)),
+	}
+
+	for _, k := range kr.keys {
+		if !k.isAsymmetric() {
+			continue
+		}
+
+		jwk := &JWK{
+			KeyID:     k.id,
+			Algorithm: k.method.Alg(),
+			Use:       "sig",
+		}
+
+		switch publicKey := k.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = encode(publicKey.N.Bytes())
+			jwk.E = encode(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = encode(publicKey)
+		}
+
+		res.Keys = append(res.Keys, jwk)
+	}
+
+	sort.Slice(res.Keys, func(i, j int) bool {
+		return res.Keys[i].KeyID < res.Keys[j].KeyID
+	})
+
+	return res
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,219 @@
+// Package keyring provides a set of JWT signing keys identified by the kid header.
+// One of the keys signs new tokens, all of them verify tokens,
+// so a key can be rotated without invalidating tokens signed by the previous one.
+package keyring
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+type (
+	// Configuration represents the configuration of the keyring.
+	Configuration struct {
+		SigningKeyID string              // ID of the key used to sign new tokens.
+		Keys         []*KeyConfiguration // Keys used to verify tokens.
+	}
+
+	// KeyConfiguration represents the configuration of a single key.
+	KeyConfiguration struct {
+		ID        string // ID of the key, it's put into the kid header of signed tokens.
+		Algorithm string // Signing algorithm: HS256, RS256 or EdDSA.
+		Secret    []byte // Secret of the HS256 key.
+		PEMFile   string // Path to the PEM file with a private or a public key of RS256 and EdDSA keys.
+	}
+
+	// Keyring contains keys to sign and verify tokens.
+	Keyring struct {
+		signingKey *key
+		keys       map[string]*key
+	}
+
+	key struct {
+		id        string
+		method    jwt.SigningMethod
+		signKey   any // nil if the key can only verify tokens
+		verifyKey any
+	}
+)
+
+// Supported signing algorithms.
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var (
+	errNoKeys              = errors.New("keyring has no keys")
+	errKeyIDIsEmpty        = errors.New("key ID is empty")
+	errSecretIsEmpty       = errors.New("secret is empty")
+	errUnknownAlgorithm    = errors.New("unknown signing algorithm")
+	errUnknownKeyID        = errors.New("unknown key ID")
+	errKeyCannotSign       = errors.New("key has no private part and cannot sign tokens")
+	errUnexpectedAlgorithm = errors.New("unexpected signing algorithm")
+)
+
+// NewKeyring loads keys described in the configuration.
+// If the signing key ID isn't set, the first key is used to sign tokens.
+func NewKeyring(c *Configuration) (*Keyring, error) {
+	if c == nil || len(c.Keys) == 0 {
+		return nil, errNoKeys
+	}
+
+	kr := &Keyring{
+		keys: make(map[string]*key, len(c.Keys)),
+	}
+
+	for _, v := range c.Keys {
+		k, err := loadKey(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load key %s: %w", v.ID, err)
+		}
+
+		if _, ok := kr.keys[k.id]; ok {
+			return nil, fmt.Errorf("key %s is repeated", k.id)
+		}
+
+		kr.keys[k.id] = k
+	}
+
+	signingKeyID := c.SigningKeyID
+	if signingKeyID == "" {
+		signingKeyID = c.Keys[0].ID
+	}
+
+	signingKey, ok := kr.keys[signingKeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnknownKeyID, signingKeyID)
+	}
+
+	if signingKey.signKey == nil {
+		return nil, fmt.Errorf("failed to use key %s for signing: %w", signingKeyID, errKeyCannotSign)
+	}
+
+	kr.signingKey = signingKey
+
+	return kr, nil
+}
+
+// Sign signs the claims with the signing key and puts its ID into the kid header.
+func (kr *Keyring) Sign(claims jwt.Claims) (string, error) {
+	k := kr.signingKey
+
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.id
+
+	return token.SignedString(k.signKey)
+}
+
+// Keyfunc returns the key to verify the token by its kid header.
+// Tokens without the header are verified by the signing key.
+func (kr *Keyring) Keyfunc(token *jwt.Token) (any, error) {
+	k := kr.signingKey
+
+	if kid, ok := token.Header["kid"].(string); ok {
+		if k, ok = kr.keys[kid]; !ok {
+			return nil, fmt.Errorf("%w: %s", errUnknownKeyID, kid)
+		}
+	}
+
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("%w: %s", errUnexpectedAlgorithm, token.Method.Alg())
+	}
+
+	return k.verifyKey, nil
+}
+
+func loadKey(c *KeyConfiguration) (*key, error) {
+	if c.ID == "" {
+		return nil, errKeyIDIsEmpty
+	}
+
+	switch c.Algorithm {
+	case AlgorithmHS256:
+		if len(c.Secret) == 0 {
+			return nil, errSecretIsEmpty
+		}
+
+		return &key{
+			id:        c.ID,
+			method:    jwt.SigningMethodHS256,
+			signKey:   c.Secret,
+			verifyKey: c.Secret,
+		}, nil
+	case AlgorithmRS256:
+		return loadPEMKey(c, jwt.SigningMethodRS256)
+	case AlgorithmEdDSA:
+		return loadPEMKey(c, jwt.SigningMethodEdDSA)
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownAlgorithm, c.Algorithm)
+	}
+}
+
+// loadPEMKey loads a private key from the PEM file or, if it's not a private key, a public one.
+func loadPEMKey(c *KeyConfiguration, method jwt.SigningMethod) (*key, error) {
+	data, err := os.ReadFile(c.PEMFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PEM file: %w", err)
+	}
+
+	k := &key{
+		id:     c.ID,
+		method: method,
+	}
+
+	if method == jwt.SigningMethodRS256 {
+		if privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			k.signKey = privateKey
+			k.verifyKey = &privateKey.PublicKey
+
+			return k, nil
+		}
+
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA key: %w", err)
+		}
+
+		k.verifyKey = publicKey
+
+		return k, nil
+	}
+
+	if privateKey, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		edPrivateKey, ok := privateKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("unsupported EdDSA private key type %T", privateKey)
+		}
+
+		k.signKey = edPrivateKey
+		k.verifyKey = edPrivateKey.Public()
+
+		return k, nil
+	}
+
+	publicKey, err := jwt.ParseEdPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse EdDSA key: %w", err)
+	}
+
+	k.verifyKey = publicKey
+
+	return k, nil
+}
+
+// isAsymmetric reports whether the key can be published.
+func (k *key) isAsymmetric() bool {
+	switch k.verifyKey.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,110 @@
+package password_hasher
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+type (
+	argon2idHasher struct {
+		params argon2idParams
+	}
+
+	argon2idParams struct {
+		memory      uint32
+		iterations  uint32
+		parallelism uint8
+	}
+)
+
+const (
+	argon2idPrefix     = "$argon2id$"
+	argon2idSaltLength = 16
+	argon2idKeyLength  = 32
+	// An encoded hash looks like $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>.
+	argon2idHashPartsCount = 6
+)
+
+var errInvalidArgon2idHash = errors.New("invalid argon2id hash")
+
+func (h *argon2idHasher) hash(password string) (string, error) {
+	salt := make([]byte, argon2idSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	p := h.params
+	key := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, argon2idKeyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		p.memory,
+		p.iterations,
+		p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *argon2idHasher) verify(encodedHash, password string) (bool, error) {
+	p, salt, key, err := decodeArgon2idHash(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	otherKey := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, uint32(len(key)))
+
+	return subtle.ConstantTimeCompare(key, otherKey) == 1, nil
+}
+
+func (h *argon2idHasher) needsRehash(encodedHash string) bool {
+	p, _, _, err := decodeArgon2idHash(encodedHash)
+	if err != nil {
+		return true
+	}
+
+	return p != h.params
+}
+
+func decodeArgon2idHash(encodedHash string) (argon2idParams, []byte, []byte, error) {
+	var (
+		p     argon2idParams
+		parts = strings.Split(encodedHash, "$")
+	)
+
+	if len(parts) != argon2idHashPartsCount {
+		return p, nil, nil, errInvalidArgon2idHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return p, nil, nil, fmt.Errorf("%w: %v", errInvalidArgon2idHash, err)
+	}
+
+	if version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("%w: unsupported version %d", errInvalidArgon2idHash, version)
+	}
+
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism)
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("%w: %v", errInvalidArgon2idHash, err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("%w: %v", errInvalidArgon2idHash, err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("%w: %v", errInvalidArgon2idHash, err)
+	}
+
+	return p, salt, key, nil
+}
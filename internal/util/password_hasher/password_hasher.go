@@ -0,0 +1,128 @@
+// Package password_hasher provides hashing of passwords with bcrypt or argon2id.
+// Hashes are encoded in the PHC string format (bcrypt uses its own modular crypt format),
+// so the algorithm and parameters of any stored hash can be detected
+// and outdated hashes can be upgraded transparently.
+package password_hasher
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type (
+	// PasswordHasher hashes passwords and verifies them against stored hashes.
+	PasswordHasher interface {
+		// Hash returns the encoded hash of the password made with the configured algorithm.
+		Hash(password string) (string, error)
+
+		// Verify checks if the password matches the encoded hash made with any supported algorithm.
+		Verify(encodedHash, password string) (bool, error)
+
+		// NeedsRehash reports whether the encoded hash was made with another algorithm or other parameters
+		// than the configured ones.
+		NeedsRehash(encodedHash string) bool
+	}
+
+	// Configuration represents the configuration of the password hasher.
+	Configuration struct {
+		Algorithm         string // Algorithm of new hashes: bcrypt or argon2id.
+		BcryptCost        int    // Cost of bcrypt hashes.
+		Argon2Memory      uint32 // Memory used by argon2id in KiB.
+		Argon2Iterations  uint32 // Number of argon2id iterations.
+		Argon2Parallelism uint8  // Number of argon2id threads.
+	}
+
+	passwordHasher struct {
+		algorithm string
+		bcrypt    *bcryptHasher
+		argon2id  *argon2idHasher
+	}
+)
+
+// Supported algorithms.
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+var (
+	errUnknownAlgorithm   = errors.New("unknown password hashing algorithm")
+	errUnknownHashFormat  = errors.New("unknown password hash format")
+	errInvalidBcryptCost  = fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	errInvalidArgon2Param = errors.New("argon2id memory, iterations and parallelism must be greater than 0")
+)
+
+// NewPasswordHasher creates a new PasswordHasher with the given configuration.
+func NewPasswordHasher(c *Configuration) (PasswordHasher, error) {
+	switch c.Algorithm {
+	case AlgorithmBcrypt, AlgorithmArgon2id:
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownAlgorithm, c.Algorithm)
+	}
+
+	if c.BcryptCost < bcrypt.MinCost || c.BcryptCost > bcrypt.MaxCost {
+		return nil, errInvalidBcryptCost
+	}
+
+	if c.Argon2Memory == 0 || c.Argon2Iterations == 0 || c.Argon2Parallelism == 0 {
+		return nil, errInvalidArgon2Param
+	}
+
+	return &passwordHasher{
+		algorithm: c.Algorithm,
+		bcrypt: &bcryptHasher{
+			cost: c.BcryptCost,
+		},
+		argon2id: &argon2idHasher{
+			params: argon2idParams{
+				memory:      c.Argon2Memory,
+				iterations:  c.Argon2Iterations,
+				parallelism: c.Argon2Parallelism,
+			},
+		},
+	}, nil
+}
+
+func (h *passwordHasher) Hash(password string) (string, error) {
+	if h.algorithm == AlgorithmArgon2id {
+		return h.argon2id.hash(password)
+	}
+
+	return h.bcrypt.hash(password)
+}
+
+func (h *passwordHasher) Verify(encodedHash, password string) (bool, error) {
+	switch {
+	case isArgon2idHash(encodedHash):
+		return h.argon2id.verify(encodedHash, password)
+	case isBcryptHash(encodedHash):
+		return h.bcrypt.verify(encodedHash, password)
+	default:
+		return false, errUnknownHashFormat
+	}
+}
+
+func (h *passwordHasher) NeedsRehash(encodedHash string) bool {
+	if h.algorithm == AlgorithmArgon2id {
+		return !isArgon2idHash(encodedHash) || h.argon2id.needsRehash(encodedHash)
+	}
+
+	return !isBcryptHash(encodedHash) || h.bcrypt.needsRehash(encodedHash)
+}
+
+func isArgon2idHash(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, argon2idPrefix)
+}
+
+func isBcryptHash(encodedHash string) bool {
+	for _, prefix := range bcryptPrefixes {
+		if strings.HasPrefix(encodedHash, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
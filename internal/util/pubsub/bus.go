@@ -0,0 +1,86 @@
+// Package pubsub provides a simple in-process publish/subscribe bus.
+package pubsub
+
+import "sync"
+
+// Bus delivers published messages to all current subscribers.
+// Publishing never blocks: a message is dropped for a subscriber whose buffer is full.
+type Bus[T any] struct {
+	subscribers map[uint64]chan T
+	nextID      uint64
+	capacity    int
+	isClosed    bool
+	mu          sync.RWMutex
+}
+
+// NewBus creates a new Bus, capacity is the buffer size of every subscription channel.
+func NewBus[T any](capacity int) *Bus[T] {
+	return &Bus[T]{
+		subscribers: make(map[uint64]chan T),
+		capacity:    capacity,
+	}
+}
+
+// Publish sends the message to all subscribers.
+// Returns the number of subscribers that didn't receive the message because their buffers were full.
+func (b *Bus[T]) Publish(v T) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var droppedCount int
+
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- v:
+		default:
+			droppedCount++
+		}
+	}
+
+	return droppedCount
+}
+
+// Subscribe creates a new subscription.
+// It returns the channel with published messages and the function that cancels the subscription.
+// The channel is closed when the subscription is cancelled or the bus is closed.
+func (b *Bus[T]) Subscribe() (<-chan T, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan T, b.capacity)
+	if b.isClosed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = ch
+
+	return ch, func() {
+		b.unsubscribe(id)
+	}
+}
+
+// Close cancels all subscriptions.
+func (b *Bus[T]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for id, ch := range b.subscribers {
+		close(ch)
+		delete(b.subscribers, id)
+	}
+
+	b.isClosed = true
+}
+
+func (b *Bus[T]) unsubscribe(id uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ch, ok := b.subscribers[id]; ok {
+		close(ch)
+		delete(b.subscribers, id)
+	}
+}
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE friendships (
+    user_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE, -- ID пользователя
+    friend_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE, -- ID друга
+    created_at timestamp NOT NULL DEFAULT now(), -- Дата / время добавления в друзья
+    PRIMARY KEY (user_id, friend_id),
+    CHECK (user_id <> friend_id)
+);
+
+CREATE INDEX friendships_friend_id_idx ON friendships USING btree(friend_id, user_id);
+
+COMMENT ON TABLE friendships IS 'Список друзей пользователей';
+
+COMMENT ON COLUMN friendships.user_id IS 'ID пользователя';
+
+COMMENT ON COLUMN friendships.friend_id IS 'ID друга';
+
+COMMENT ON COLUMN friendships.created_at IS 'Дата / время добавления в друзья';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE friendships;
+
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE posts (
+    id bigserial PRIMARY KEY, -- ID поста, генерируется автоматически
+    author_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE, -- ID автора поста
+    text varchar(5000) NOT NULL, -- Текст поста
+    created_at timestamp NOT NULL DEFAULT now(), -- Дата / время создания поста
+    updated_at timestamp DEFAULT NULL -- Дата / время последнего изменения поста
+);
+
+CREATE INDEX posts_author_id_idx ON posts USING btree(author_id, id DESC);
+
+COMMENT ON TABLE posts IS 'Список постов пользователей';
+
+COMMENT ON COLUMN posts.id IS 'ID поста, генерируется автоматически';
+
+COMMENT ON COLUMN posts.author_id IS 'ID автора поста';
+
+COMMENT ON COLUMN posts.text IS 'Текст поста';
+
+COMMENT ON COLUMN posts.created_at IS 'Дата / время создания поста';
+
+COMMENT ON COLUMN posts.updated_at IS 'Дата / время последнего изменения поста';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE posts;
+
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Таблица не содержит внешних ключей, чтобы её можно было шардировать по dialog_id.
+CREATE TABLE messages (
+    dialog_id varchar(41) NOT NULL, -- ID диалога, составленный из ID участников по возрастанию
+    id bigserial NOT NULL, -- ID сообщения, генерируется автоматически
+    sender_id bigint NOT NULL, -- ID отправителя
+    receiver_id bigint NOT NULL, -- ID получателя
+    text varchar(4000) NOT NULL, -- Текст сообщения
+    created_at timestamp NOT NULL DEFAULT now(), -- Дата / время отправки сообщения
+    PRIMARY KEY (dialog_id, id)
+);
+
+COMMENT ON TABLE messages IS 'Список сообщений в диалогах пользователей';
+
+COMMENT ON COLUMN messages.dialog_id IS 'ID диалога, составленный из ID участников по возрастанию';
+
+COMMENT ON COLUMN messages.id IS 'ID сообщения, генерируется автоматически';
+
+COMMENT ON COLUMN messages.sender_id IS 'ID отправителя';
+
+COMMENT ON COLUMN messages.receiver_id IS 'ID получателя';
+
+COMMENT ON COLUMN messages.text IS 'Текст сообщения';
+
+COMMENT ON COLUMN messages.created_at IS 'Дата / время отправки сообщения';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE messages;
+
+-- +goose StatementEnd
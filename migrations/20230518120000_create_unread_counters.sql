@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE unread_counters (
+    user_id bigint NOT NULL, -- ID пользователя, получившего сообщения
+    partner_id bigint NOT NULL, -- ID собеседника
+    unread_count bigint NOT NULL DEFAULT 0, -- Количество непрочитанных сообщений
+    last_read_message_id bigint NOT NULL DEFAULT 0, -- ID последнего прочитанного сообщения
+    updated_at timestamp NOT NULL DEFAULT now(), -- Дата / время последнего изменения счётчика
+    PRIMARY KEY (user_id, partner_id),
+    CHECK (unread_count >= 0)
+);
+
+COMMENT ON TABLE unread_counters IS 'Счётчики непрочитанных сообщений в диалогах';
+
+COMMENT ON COLUMN unread_counters.user_id IS 'ID пользователя, получившего сообщения';
+
+COMMENT ON COLUMN unread_counters.partner_id IS 'ID собеседника';
+
+COMMENT ON COLUMN unread_counters.unread_count IS 'Количество непрочитанных сообщений';
+
+COMMENT ON COLUMN unread_counters.last_read_message_id IS 'ID последнего прочитанного сообщения';
+
+COMMENT ON COLUMN unread_counters.updated_at IS 'Дата / время последнего изменения счётчика';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE unread_counters;
+
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE sessions (
+    id varchar(32) PRIMARY KEY, -- ID refresh-токена
+    family_id varchar(32) NOT NULL, -- ID семейства refresh-токенов, начатого при входе
+    user_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE, -- ID пользователя
+    created_at timestamptz NOT NULL DEFAULT now(), -- Дата / время выдачи токена
+    expires_at timestamptz NOT NULL, -- Дата / время истечения токена
+    rotated_at timestamptz -- Дата / время обмена токена на новый
+);
+
+CREATE INDEX sessions_family_id_idx ON sessions USING btree(family_id);
+
+CREATE INDEX sessions_expires_at_idx ON sessions USING btree(expires_at);
+
+COMMENT ON TABLE sessions IS 'Сессии пользователей (выданные refresh-токены)';
+
+COMMENT ON COLUMN sessions.id IS 'ID refresh-токена';
+
+COMMENT ON COLUMN sessions.family_id IS 'ID семейства refresh-токенов, начатого при входе';
+
+COMMENT ON COLUMN sessions.user_id IS 'ID пользователя';
+
+COMMENT ON COLUMN sessions.created_at IS 'Дата / время выдачи токена';
+
+COMMENT ON COLUMN sessions.expires_at IS 'Дата / время истечения токена';
+
+COMMENT ON COLUMN sessions.rotated_at IS 'Дата / время обмена токена на новый';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE sessions;
+
+-- +goose StatementEnd
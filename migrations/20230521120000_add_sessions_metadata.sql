@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions
+    ADD COLUMN last_seen_at timestamptz NOT NULL DEFAULT now(), -- Дата / время последнего запроса с токеном
+    ADD COLUMN user_agent text NOT NULL DEFAULT '', -- User agent клиента, которому выдан токен
+    ADD COLUMN ip varchar(45) NOT NULL DEFAULT ''; -- IP-адрес клиента, которому выдан токен
+
+CREATE INDEX sessions_user_id_idx ON sessions USING btree(user_id);
+
+COMMENT ON COLUMN sessions.last_seen_at IS 'Дата / время последнего запроса с токеном';
+
+COMMENT ON COLUMN sessions.user_agent IS 'User agent клиента, которому выдан токен';
+
+COMMENT ON COLUMN sessions.ip IS 'IP-адрес клиента, которому выдан токен';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX sessions_user_id_idx;
+
+ALTER TABLE sessions
+    DROP COLUMN last_seen_at,
+    DROP COLUMN user_agent,
+    DROP COLUMN ip;
+
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE user_role AS enum (
+    'USER',
+    'ADMIN'
+);
+
+ALTER TABLE users
+    ADD COLUMN role user_role NOT NULL DEFAULT 'USER'; -- Роль пользователя
+
+COMMENT ON COLUMN users.role IS 'Роль пользователя';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN role;
+
+DROP TYPE user_role;
+
+-- +goose StatementEnd
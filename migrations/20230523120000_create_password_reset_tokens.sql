@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE password_reset_tokens (
+    token_hash varchar(64) PRIMARY KEY, -- SHA-256 хеш токена сброса пароля
+    user_id bigint NOT NULL REFERENCES users (id) ON DELETE CASCADE, -- ID пользователя
+    created_at timestamptz NOT NULL DEFAULT now(), -- Дата / время создания токена
+    expires_at timestamptz NOT NULL, -- Дата / время истечения токена
+    used_at timestamptz -- Дата / время использования токена
+);
+
+CREATE INDEX password_reset_tokens_user_id_idx ON password_reset_tokens USING btree(user_id);
+
+COMMENT ON TABLE password_reset_tokens IS 'Токены сброса пароля';
+
+COMMENT ON COLUMN password_reset_tokens.token_hash IS 'SHA-256 хеш токена сброса пароля';
+
+COMMENT ON COLUMN password_reset_tokens.user_id IS 'ID пользователя';
+
+COMMENT ON COLUMN password_reset_tokens.created_at IS 'Дата / время создания токена';
+
+COMMENT ON COLUMN password_reset_tokens.expires_at IS 'Дата / время истечения токена';
+
+COMMENT ON COLUMN password_reset_tokens.used_at IS 'Дата / время использования токена';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE password_reset_tokens;
+
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE login_attempts (
+    key varchar(150) PRIMARY KEY, -- Ключ попыток входа: e-mail или IP-адрес с префиксом
+    failures bigint NOT NULL DEFAULT 0, -- Количество неудачных попыток входа подряд
+    last_failed_at timestamptz NOT NULL DEFAULT now() -- Дата / время последней неудачной попытки входа
+);
+
+CREATE INDEX login_attempts_last_failed_at_idx ON login_attempts USING btree(last_failed_at);
+
+COMMENT ON TABLE login_attempts IS 'Неудачные попытки входа';
+
+COMMENT ON COLUMN login_attempts.key IS 'Ключ попыток входа: e-mail или IP-адрес с префиксом';
+
+COMMENT ON COLUMN login_attempts.failures IS 'Количество неудачных попыток входа подряд';
+
+COMMENT ON COLUMN login_attempts.last_failed_at IS 'Дата / время последней неудачной попытки входа';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE login_attempts;
+
+-- +goose StatementEnd
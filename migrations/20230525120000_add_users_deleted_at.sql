@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN deleted_at timestamptz; -- Дата / время удаления пользователя
+
+COMMENT ON COLUMN users.deleted_at IS 'Дата / время удаления пользователя';
+
+-- E-mail удалённого пользователя можно занять повторно до окончательного удаления строки
+ALTER TABLE users
+    DROP CONSTRAINT users_email_key;
+
+CREATE UNIQUE INDEX users_email_idx ON users USING btree(email)
+WHERE
+    deleted_at IS NULL;
+
+CREATE INDEX users_deleted_at_idx ON users USING btree(deleted_at)
+WHERE
+    deleted_at IS NOT NULL;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM users
+WHERE deleted_at IS NOT NULL;
+
+DROP INDEX users_deleted_at_idx;
+
+DROP INDEX users_email_idx;
+
+ALTER TABLE users
+    ADD CONSTRAINT users_email_key UNIQUE (email);
+
+ALTER TABLE users
+    DROP COLUMN deleted_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE profile_visibility AS enum (
+    'PUBLIC',
+    'FRIENDS',
+    'ONLY_ME'
+);
+
+ALTER TABLE users
+    ADD COLUMN email_visibility profile_visibility NOT NULL DEFAULT 'FRIENDS', -- Кому виден e-mail
+    ADD COLUMN birthdate_visibility profile_visibility NOT NULL DEFAULT 'PUBLIC', -- Кому видна дата рождения
+    ADD COLUMN interests_visibility profile_visibility NOT NULL DEFAULT 'PUBLIC'; -- Кому видны интересы
+
+COMMENT ON COLUMN users.email_visibility IS 'Кому виден e-mail';
+
+COMMENT ON COLUMN users.birthdate_visibility IS 'Кому видна дата рождения';
+
+COMMENT ON COLUMN users.interests_visibility IS 'Кому видны интересы';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN email_visibility,
+    DROP COLUMN birthdate_visibility,
+    DROP COLUMN interests_visibility;
+
+DROP TYPE profile_visibility;
+
+-- +goose StatementEnd
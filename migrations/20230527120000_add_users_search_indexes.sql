@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE users
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (to_tsvector('simple', first_name || ' ' || last_name || ' ' || CASE WHEN interests_visibility = 'PUBLIC' THEN
+        interests
+    ELSE
+        ''
+    END)) STORED; -- Поисковый вектор по имени, фамилии и интересам, если они видны всем
+
+COMMENT ON COLUMN users.search_vector IS 'Поисковый вектор по имени, фамилии и интересам, если они видны всем';
+
+-- Нечёткий поиск по полному имени с опечатками
+CREATE INDEX users_full_name_trgm_idx ON users USING gin((first_name || ' ' || last_name) gin_trgm_ops);
+
+-- Полнотекстовый поиск по имени, фамилии и публичным интересам
+CREATE INDEX users_search_vector_idx ON users USING gin(search_vector);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX users_search_vector_idx;
+
+DROP INDEX users_full_name_trgm_idx;
+
+ALTER TABLE users
+    DROP COLUMN search_vector;
+
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Фильтры поиска по городу, полу и возрасту
+CREATE INDEX users_city_id_gender_birthdate_idx ON users USING btree(city_id, gender, birthdate)
+WHERE
+    deleted_at IS NULL;
+
+-- Фильтры поиска по полу и возрасту без города
+CREATE INDEX users_gender_birthdate_idx ON users USING btree(gender, birthdate)
+WHERE
+    deleted_at IS NULL;
+
+-- Фильтр поиска по ключевым словам в интересах, которые видны всем
+CREATE INDEX users_interests_trgm_idx ON users USING gin(interests gin_trgm_ops)
+WHERE
+    deleted_at IS NULL
+    AND interests_visibility = 'PUBLIC';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX users_interests_trgm_idx;
+
+DROP INDEX users_gender_birthdate_idx;
+
+DROP INDEX users_city_id_gender_birthdate_idx;
+
+-- +goose StatementEnd